@@ -12,11 +12,22 @@ import (
 	"Backend/src/core/config"
 	"Backend/src/core/database"
 	"Backend/src/core/router"
+	"Backend/src/modules/analytics"
+	"Backend/src/modules/feed"
+	"Backend/src/modules/media"
+	"Backend/src/modules/moderation"
+	"Backend/src/modules/notifications"
+	"Backend/src/modules/posts"
 )
 
 func main() {
 	// Initialize the Fiber app
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// Bodies are streamed so the router can apply per-route limits before
+		// reading them; media uploads get the most room
+		BodyLimit:         2 * media.MaxSize(),
+		StreamRequestBody: true,
+	})
 
 	// Middleware
 	app.Use(recover.New())   // Recover middleware to handle panics
@@ -29,9 +40,27 @@ func main() {
 	// Connect to the database
 	database.ConnectDB()
 
+	// Moderator deletions reuse the posts module's own deletion path
+	moderation.SetContentRemover(posts.ContentRemover{})
+
 	// Set up routes
 	router.InitialiseAndSetupRoutes(app)
 
+	// Deliver queued real-time notifications to connected clients
+	go notifications.BroadcastNotifications()
+
+	// Close polls that reached their closing time and notify their authors
+	go posts.ClosePolls()
+
+	// Publish scheduled posts when they come due
+	go posts.PublishScheduledPosts()
+
+	// Recompute feed recommendations periodically
+	go feed.RefreshRecommendations()
+
+	// Write buffered post views and impressions in batches
+	go analytics.FlushViews()
+
 	// Get port from environment variable, default to 3000
 	port := config.Config("PORT") // Render provides this
 	if port == "" {
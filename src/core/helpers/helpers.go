@@ -1,8 +1,12 @@
 package helpers
 
 import (
+	"errors"
+	"strconv"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 // Initialize a validator instance using go-playground's validator package
@@ -25,14 +29,43 @@ func HandleSuccess(context *fiber.Ctx, statusCode int, message string, data inte
 
 // HandleError sends a structured JSON response for errors.
 func HandleError(context *fiber.Ctx, statusCode int, message string, err error) error {
+	var errMessage interface{}
+	if err != nil {
+		errMessage = err.Error()
+	}
 	return context.Status(statusCode).JSON(fiber.Map{
 		"status":  "error",
 		"message": message,
-		"error":   err.Error(),
+		"error":   errMessage,
 		"data":    nil,
 	})
 }
 
+// HandleFiberError sends the status and message of a *fiber.Error. Module
+// helpers report request problems that way; any other error is answered with
+// a 500 carrying fallback.
+func HandleFiberError(context *fiber.Ctx, err error, fallback string) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return HandleError(context, fiberErr.Code, fiberErr.Message, err)
+	}
+	return HandleError(context, fiber.StatusInternalServerError, fallback, err)
+}
+
+// CurrentUserID returns the ID of the user authenticated by the Protected
+// middleware. Errors are *fiber.Error values, ready for HandleFiberError.
+func CurrentUserID(context *fiber.Ctx) (uuid.UUID, error) {
+	userID, ok := context.Locals("user_id").(string)
+	if !ok || userID == "" {
+		return uuid.Nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or missing user_id")
+	}
+	parsed, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format")
+	}
+	return parsed, nil
+}
+
 // GenerateErrorResponse creates a custom Fiber-compatible error response object.
 func GenerateErrorResponse(message string, err error) fiber.Map {
 	return fiber.Map{
@@ -42,3 +75,20 @@ func GenerateErrorResponse(message string, err error) fiber.Map {
 		"data":    nil,
 	}
 }
+
+// ParsePagination reads the limit and offset query parameters, falling back to
+// defaultLimit and capping the limit at maxLimit.
+func ParsePagination(context *fiber.Ctx, defaultLimit, maxLimit int) (int, int) {
+	limit, err := strconv.Atoi(context.Query("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	offset, err := strconv.Atoi(context.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
@@ -1,11 +1,17 @@
 package middleware
 
 import (
-	"Backend/src/core/config"  // Adjust this import to your config package path
+	"Backend/src/core/config" // Adjust this import to your config package path
+	"Backend/src/core/database"
 	"Backend/src/core/helpers" // Adjust this import to your helpers package path
+	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -24,6 +30,9 @@ func Protected() fiber.Handler {
 			user := c.Locals("user").(*jwt.Token)
 			claims := user.Claims.(jwt.MapClaims)
 			if userID, ok := claims["user_id"].(string); ok {
+				if until := suspendedUntil(userID); until != nil {
+					return helpers.HandleError(c, fiber.StatusForbidden, "Your account is suspended until "+until.UTC().Format(time.RFC1123), nil)
+				}
 				c.Locals("user_id", userID)
 				return c.Next()
 			}
@@ -32,6 +41,46 @@ func Protected() fiber.Handler {
 	})
 }
 
+// ProtectedSocket authenticates WebSocket upgrades, which cannot carry an
+// Authorization header from browsers, by the JWT in the ?token query
+// parameter. Requests that are not upgrades or lack a valid token are refused
+// before the upgrade, and the handler reads the caller from Locals("user_id").
+func ProtectedSocket() fiber.Handler {
+	jwtSecret := config.Config("JWT_SECRET")
+	if jwtSecret == "" {
+		panic("JWT_SECRET is not set in the environment variables") // Panic to prevent startup
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return helpers.HandleError(c, fiber.StatusUpgradeRequired, "WebSocket upgrade required", nil)
+		}
+		tokenString := c.Query("token")
+		if tokenString == "" {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Missing or malformed JWT", nil)
+		}
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+			}
+			return []byte(jwtSecret), nil
+		})
+		if err != nil || !token.Valid {
+			return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or expired JWT", err)
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		userID, _ := claims["user_id"].(string)
+		if !ok || userID == "" {
+			return helpers.HandleError(c, fiber.StatusUnauthorized, "User ID missing in token", nil)
+		}
+		if until := suspendedUntil(userID); until != nil {
+			return helpers.HandleError(c, fiber.StatusForbidden, "Your account is suspended until "+until.UTC().Format(time.RFC1123), nil)
+		}
+		c.Locals("user_id", userID)
+		return c.Next()
+	}
+}
+
 // jwtError handles JWT-related errors
 func jwtError(c *fiber.Ctx, err error) error {
 	if err.Error() == "Missing or malformed JWT" {
@@ -39,3 +88,55 @@ func jwtError(c *fiber.Ctx, err error) error {
 	}
 	return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or expired JWT", err)
 }
+
+// suspensionCacheTTL bounds how long a suspension lookup is reused, so a
+// suspension reaches other instances within this delay.
+const suspensionCacheTTL = time.Minute
+
+type suspensionEntry struct {
+	until     *time.Time
+	checkedAt time.Time
+}
+
+var (
+	suspensionMu    sync.Mutex
+	suspensionCache = make(map[string]suspensionEntry)
+)
+
+// suspendedUntil returns the end of the user's suspension, or nil if they are
+// not suspended. Lookup failures let the request through.
+func suspendedUntil(userID string) *time.Time {
+	now := time.Now()
+	suspensionMu.Lock()
+	entry, ok := suspensionCache[userID]
+	suspensionMu.Unlock()
+
+	if !ok || now.Sub(entry.checkedAt) > suspensionCacheTTL {
+		var until []time.Time
+		if err := database.DB.Table("users").Where("id = ? AND suspended_until IS NOT NULL", userID).
+			Pluck("suspended_until", &until).Error; err != nil {
+			log.Printf("Error checking suspension of %s: %v\n", userID, err)
+			return nil
+		}
+		entry = suspensionEntry{checkedAt: now}
+		if len(until) > 0 {
+			entry.until = &until[0]
+		}
+		suspensionMu.Lock()
+		suspensionCache[userID] = entry
+		suspensionMu.Unlock()
+	}
+
+	if entry.until != nil && entry.until.After(now) {
+		return entry.until
+	}
+	return nil
+}
+
+// ForgetSuspension drops the cached suspension state of the user, so a change
+// takes effect on this instance immediately.
+func ForgetSuspension(userID string) {
+	suspensionMu.Lock()
+	delete(suspensionCache, userID)
+	suspensionMu.Unlock()
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"Backend/src/core/helpers"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit rejects request bodies larger than the limit returned for the
+// request, before they are read. The app must stream request bodies: streamed
+// bodies are not held to the server's own BodyLimit, so this is what enforces it.
+func BodyLimit(limit func(*fiber.Ctx) int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		maxSize := limit(c)
+		req := c.Request()
+		if req.Header.ContentLength() > maxSize {
+			return bodyTooLarge(c, maxSize)
+		}
+
+		// Chunked bodies carry no length up front, so read at most one byte past the limit
+		if req.IsBodyStream() {
+			body, err := io.ReadAll(io.LimitReader(req.BodyStream(), int64(maxSize)+1))
+			if err != nil {
+				return helpers.HandleError(c, fiber.StatusBadRequest, "Failed to read request body", err)
+			}
+			if len(body) > maxSize {
+				return bodyTooLarge(c, maxSize)
+			}
+			req.SetBody(body)
+		}
+		return c.Next()
+	}
+}
+
+// bodyTooLarge answers 413 and closes the connection, since the rest of the
+// body is never read and cannot be mistaken for the next request.
+func bodyTooLarge(c *fiber.Ctx, maxSize int) error {
+	c.Context().SetConnectionClose()
+	return helpers.HandleError(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("Request body may be at most %d bytes", maxSize), nil)
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bookmarkable item types
+const (
+	BookmarkItemPost     = "post"
+	BookmarkItemEvent    = "event"
+	BookmarkItemWorkshop = "workshop"
+	BookmarkItemProject  = "project"
+)
+
+// Bookmark is an item a user saved, optionally filed in one of their collections.
+type Bookmark struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	ItemType     string    `json:"item_type" gorm:"type:varchar(20);not null"`
+	ItemID       uuid.UUID `json:"item_id" gorm:"type:uuid;not null"`
+	CollectionID *int      `json:"collection_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BookmarkCollection is a named group of a user's bookmarks.
+type BookmarkCollection struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	Name      string    `json:"name" gorm:"type:varchar(100);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
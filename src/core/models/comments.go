@@ -6,11 +6,15 @@ import (
 )
 
 type Comment struct {
-	ID        uuid.UUID `gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
-	PostID    uuid.UUID `gorm:"column:post_id;type:uuid;not null" json:"post_id"`
-	UserID    uuid.UUID `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
-	Content   string    `gorm:"column:content;type:text;not null" json:"content"`
-	CreatedAt time.Time `gorm:"column:created_at;type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	ID        uuid.UUID  `gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	PostID    uuid.UUID  `gorm:"column:post_id;type:uuid;not null" json:"post_id"`
+	UserID    uuid.UUID  `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
+	ParentID  *uuid.UUID `gorm:"column:parent_id;type:uuid" json:"parent_id,omitempty"`
+	Content   string     `gorm:"column:content;type:text;not null" json:"content"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	EditedAt  *time.Time `gorm:"column:edited_at;type:timestamp with time zone" json:"edited_at,omitempty"`
+	DeletedAt *time.Time `gorm:"column:deleted_at;type:timestamp with time zone" json:"-"`
+	HiddenAt  *time.Time `gorm:"column:hidden_at;type:timestamp" json:"-"`
 }
 
 func (Comment) TableName() string {
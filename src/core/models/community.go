@@ -2,6 +2,15 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
+)
+
+// Community visibility modes
+const (
+	CommunityVisibilityPublic  = "public"
+	CommunityVisibilityRequest = "request"
+	CommunityVisibilityInvite  = "invite"
 )
 
 // Community struct maps to the communities table
@@ -9,9 +18,24 @@ type Community struct {
 	ID          int       `gorm:"column:id;type:serial;primaryKey" json:"id"`
 	Name        string    `gorm:"column:name;type:varchar(255);not null" json:"name"`
 	Description string    `gorm:"column:description;type:text" json:"description"`
+	Visibility  string    `gorm:"column:visibility;type:varchar(20);not null;default:public" json:"visibility"`
+	Category    string    `gorm:"column:category;type:varchar(100)" json:"category"`
+	IconURL     string    `gorm:"column:icon_url;type:text" json:"icon_url"`
+	CreatedBy   uuid.UUID `gorm:"column:created_by;type:uuid" json:"created_by"`
 	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	Tags        []string  `gorm:"-" json:"tags"`
 }
 
 func (Community) TableName() string {
 	return "communities"
-}
\ No newline at end of file
+}
+
+// CommunityTag links a community to an entry in the shared tags vocabulary
+type CommunityTag struct {
+	CommunityID int `gorm:"column:community_id;primaryKey"`
+	TagID       int `gorm:"column:tag_id;primaryKey"`
+}
+
+func (CommunityTag) TableName() string {
+	return "community_tags"
+}
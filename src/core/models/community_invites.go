@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommunityInvite struct maps to the community_invites table
+type CommunityInvite struct {
+	ID          int        `gorm:"column:id;type:serial;primaryKey" json:"id"`
+	CommunityID int        `gorm:"column:community_id;type:int;not null" json:"community_id"`
+	Code        string     `gorm:"column:code;type:varchar(32);unique;not null" json:"code"`
+	CreatedBy   uuid.UUID  `gorm:"column:created_by;type:uuid;not null" json:"created_by"`
+	ExpiresAt   *time.Time `gorm:"column:expires_at;type:timestamp" json:"expires_at,omitempty"`
+	MaxUses     *int       `gorm:"column:max_uses;type:int" json:"max_uses,omitempty"`
+	Uses        int        `gorm:"column:uses;type:int;not null;default:0" json:"uses"`
+	Revoked     bool       `gorm:"column:revoked;type:boolean;not null;default:false" json:"revoked"`
+	CreatedAt   time.Time  `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (CommunityInvite) TableName() string {
+	return "community_invites"
+}
+
+// Join request statuses
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestDenied   = "denied"
+)
+
+// CommunityJoinRequest struct maps to the community_join_requests table
+type CommunityJoinRequest struct {
+	ID          int        `gorm:"column:id;type:serial;primaryKey" json:"id"`
+	CommunityID int        `gorm:"column:community_id;type:int;not null" json:"community_id"`
+	UserID      uuid.UUID  `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
+	Message     string     `gorm:"column:message;type:text" json:"message"`
+	Status      string     `gorm:"column:status;type:varchar(20);not null;default:pending" json:"status"`
+	ReviewedBy  *uuid.UUID `gorm:"column:reviewed_by;type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time `gorm:"column:reviewed_at;type:timestamp" json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (CommunityJoinRequest) TableName() string {
+	return "community_join_requests"
+}
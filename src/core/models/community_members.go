@@ -7,14 +7,38 @@ import (
 	"github.com/google/uuid"
 )
 
+// Community member roles, from most to least privileged
+const (
+	CommunityRoleOwner     = "owner"
+	CommunityRoleAdmin     = "admin"
+	CommunityRoleModerator = "moderator"
+	CommunityRoleMember    = "member"
+)
+
 // CommunityMember struct maps to the community_members table
 type CommunityMember struct {
-	ID          int       `gorm:"column:id;type:serial;primaryKey" json:"id"`
-	UserID      uuid.UUID `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
-	CommunityID int       `gorm:"column:community_id;type:int;not null" json:"community_id"`
-	JoinedAt    time.Time `gorm:"column:joined_at;type:timestamp;default:CURRENT_TIMESTAMP" json:"joined_at"`
+	ID          int        `gorm:"column:id;type:serial;primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
+	CommunityID int        `gorm:"column:community_id;type:int;not null" json:"community_id"`
+	Role        string     `gorm:"column:role;type:varchar(20);not null;default:member" json:"role"`
+	MutedUntil  *time.Time `gorm:"column:muted_until;type:timestamp" json:"muted_until,omitempty"`
+	JoinedAt    time.Time  `gorm:"column:joined_at;type:timestamp;default:CURRENT_TIMESTAMP" json:"joined_at"`
 }
 
 func (CommunityMember) TableName() string {
 	return "community_members"
 }
+
+// CommunityBan struct maps to the community_bans table
+type CommunityBan struct {
+	ID          int       `gorm:"column:id;type:serial;primaryKey" json:"id"`
+	CommunityID int       `gorm:"column:community_id;type:int;not null" json:"community_id"`
+	UserID      uuid.UUID `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
+	BannedBy    uuid.UUID `gorm:"column:banned_by;type:uuid;not null" json:"banned_by"`
+	Reason      string    `gorm:"column:reason;type:text" json:"reason"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+func (CommunityBan) TableName() string {
+	return "community_bans"
+}
@@ -22,6 +22,7 @@ type Event struct {
 	Tags                 string    `json:"tags" gorm:"type:varchar(255)"`
 	AttendeeCount        int       `json:"attendee_count" gorm:"type:int"`
 	Status               string    `json:"status" gorm:"type:varchar(20);not null"`
+	CommunityID          *int      `json:"community_id,omitempty"`
 }
 
 type Workshop struct {
@@ -39,6 +40,7 @@ type Workshop struct {
     ParticipantLimit int       `json:"participant_limit" gorm:"type:int"` // Changed to int
     Status           string    `json:"status" gorm:"type:workshop_status;not null"` // Enum values
     RegistrationLink string    `json:"registration_link" gorm:"type:text"`
+    CommunityID      *int      `json:"community_id,omitempty"`
 }
 
 type Project struct {
@@ -1,12 +1,42 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// Reaction types. Rows created before reactions existed default to ReactionLike.
+const (
+	ReactionLike       = "like"
+	ReactionCelebrate  = "celebrate"
+	ReactionSupport    = "support"
+	ReactionInsightful = "insightful"
+	ReactionLove       = "love"
+	ReactionFunny      = "funny"
+)
+
+// ReactionTypes lists every reaction a user may leave on a post.
+var ReactionTypes = []string{ReactionLike, ReactionCelebrate, ReactionSupport, ReactionInsightful, ReactionLove, ReactionFunny}
+
+// Like is a user's single reaction to a post.
 type Like struct {
-	UserID uuid.UUID `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
-	PostID uuid.UUID `gorm:"column:post_id;type:uuid;not null" json:"post_id"`
+	UserID       uuid.UUID `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
+	PostID       uuid.UUID `gorm:"column:post_id;type:uuid;not null" json:"post_id"`
+	ReactionType string    `gorm:"column:reaction_type;default:like" json:"reaction_type"`
+	CreatedAt    time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
 }
 
 func (Like) TableName() string {
 	return "likes"
 }
+
+// IsValidReaction reports whether reactionType is one of ReactionTypes.
+func IsValidReaction(reactionType string) bool {
+	for _, t := range ReactionTypes {
+		if t == reactionType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LinkPreview is the cached OpenGraph/Twitter card metadata of a URL. Failed
+// fetches are cached too, with Failed set, so they are not retried on every view.
+type LinkPreview struct {
+	URL         string    `json:"url" gorm:"primaryKey"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	ImageURL    string    `json:"image_url,omitempty"`
+	SiteName    string    `json:"site_name,omitempty"`
+	Failed      bool      `json:"-"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
@@ -12,6 +12,7 @@ type Message struct {
 	UserID      uuid.UUID `gorm:"column:user_id;type:uuid;not null" json:"user_id"`
 	Message     string    `gorm:"column:message;type:text;not null" json:"message"`
 	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	HiddenAt    *time.Time `gorm:"column:hidden_at;type:timestamp" json:"-"`
 }
 func (Message) TableName() string {
 	return "messages"
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reportable content types
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetMessage = "message"
+	ReportTargetUser    = "user"
+)
+
+// Report reasons
+const (
+	ReportReasonSpam           = "spam"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonHateSpeech     = "hate_speech"
+	ReportReasonViolence       = "violence"
+	ReportReasonNudity         = "nudity"
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonSelfHarm       = "self_harm"
+	ReportReasonOther          = "other"
+)
+
+// ReportReasons lists every reason a report may give.
+var ReportReasons = []string{
+	ReportReasonSpam, ReportReasonHarassment, ReportReasonHateSpeech, ReportReasonViolence,
+	ReportReasonNudity, ReportReasonMisinformation, ReportReasonSelfHarm, ReportReasonOther,
+}
+
+// Moderation case statuses
+const (
+	CaseStatusOpen      = "open"
+	CaseStatusActioned  = "actioned"
+	CaseStatusDismissed = "dismissed"
+)
+
+// Moderation actions. Actions taken automatically, such as auto-hides and
+// flags raised by the text filter, have no moderator.
+const (
+	ModerationHide    = "hide"
+	ModerationRestore = "restore"
+	ModerationDelete  = "delete"
+	ModerationWarn    = "warn"
+	ModerationSuspend = "suspend"
+	ModerationDismiss = "dismiss"
+	ModerationAssign  = "assign"
+	ModerationFlag    = "flag"
+)
+
+// Moderator is a user allowed to work the moderation queue.
+type Moderator struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"primaryKey;type:uuid"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ModerationCase groups the reports against one piece of content or user
+// while it awaits review. TargetUserID is the author of reported content.
+type ModerationCase struct {
+	ID           int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	TargetType   string     `json:"target_type"`
+	TargetID     string     `json:"target_id"`
+	TargetUserID *uuid.UUID `json:"target_user_id,omitempty"`
+	Status       string     `json:"status" gorm:"default:open"`
+	ReportCount  int        `json:"report_count"`
+	AssignedTo   *uuid.UUID `json:"assigned_to,omitempty"`
+	AutoHidden   bool       `json:"auto_hidden"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ContentReport is one user's report filed under a case.
+type ContentReport struct {
+	ID         int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	CaseID     int       `json:"case_id"`
+	ReporterID uuid.UUID `json:"reporter_id" gorm:"type:uuid"`
+	Reason     string    `json:"reason"`
+	Details    string    `json:"details,omitempty"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ModerationAction is an entry of the moderation audit trail.
+type ModerationAction struct {
+	ID           int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	CaseID       *int       `json:"case_id,omitempty"`
+	ModeratorID  *uuid.UUID `json:"moderator_id,omitempty"`
+	Action       string     `json:"action"`
+	TargetType   string     `json:"target_type"`
+	TargetID     string     `json:"target_id"`
+	TargetUserID *uuid.UUID `json:"target_user_id,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Poll limits
+const (
+	PollMinOptions = 2
+	PollMaxOptions = 10
+)
+
+// Poll holds the settings of a poll post. ClosedAt is set once the poll has
+// passed ClosesAt and its author was notified.
+type Poll struct {
+	PostID         uuid.UUID  `json:"post_id" gorm:"primaryKey;type:uuid"`
+	MultipleChoice bool       `json:"multiple_choice"`
+	ClosesAt       *time.Time `json:"closes_at,omitempty"`
+	ClosedAt       *time.Time `json:"-"`
+}
+
+// PollOption is one of the answers of a poll, in display order.
+type PollOption struct {
+	ID       int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID   uuid.UUID `json:"post_id" gorm:"type:uuid;not null"`
+	Position int       `json:"position"`
+	Text     string    `json:"text" gorm:"type:varchar(100);not null"`
+}
+
+// PollVote records a user choosing an option. Single-choice polls allow one
+// row per user; multiple-choice polls one per user and option.
+type PollVote struct {
+	PostID    uuid.UUID `json:"post_id" gorm:"type:uuid;not null"`
+	OptionID  int       `json:"option_id" gorm:"not null"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -18,6 +18,25 @@ func (ta TextArray) Value() (driver.Value, error) {
 	return json.Marshal(ta)
 }
 
+// Post types
+const (
+	PostTypePost         = "post"
+	PostTypeAnnouncement = "announcement"
+	PostTypeRepost       = "repost"
+	PostTypeQuote        = "quote"
+	PostTypePoll         = "poll"
+)
+
+// Post visibilities. Community posts are shown to the community's members
+// only; the author always sees their own posts.
+const (
+	PostVisibilityPublic    = "public"
+	PostVisibilityFollowers = "followers"
+	PostVisibilityMutual    = "mutual"
+	PostVisibilityCommunity = "community"
+	PostVisibilityPrivate   = "private"
+)
+
 type Post struct {
 	ID            uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
 	UserID        uuid.UUID `json:"user_id"`
@@ -25,8 +44,42 @@ type Post struct {
     ProfilePicURL string    `json:"profile_pic_url,omitempty"` // User's profile picture URL
 	Content       string    `json:"content"`
 	MediaURL      string    `json:"media_url,omitempty"`
+	CommunityID   *int      `json:"community_id,omitempty"`
+	PostType      string    `json:"post_type" gorm:"default:post"`
+	Visibility    string    `json:"visibility" gorm:"default:public"`
+	RepostOfID    *uuid.UUID `json:"repost_of_id,omitempty"`
 	LikesCount    int       `json:"likes_count,omitempty"`
 	CommentsCount int       `json:"comments_count,omitempty"`
 	CreatedAt     time.Time `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"default:CURRENT_TIMESTAMP"`
+	EditedAt      *time.Time `json:"edited_at,omitempty"`
+	DeletedAt     *time.Time `json:"-"`
+	HiddenAt      *time.Time `json:"-"`
+	Attachments   []PostAttachment `json:"attachments,omitempty" gorm:"-"`
+}
+
+// PostEdit keeps the content a post had before one of its edits.
+type PostEdit struct {
+	ID       int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID   uuid.UUID `json:"post_id" gorm:"type:uuid;not null"`
+	Content  string    `json:"content" gorm:"type:text;not null"`
+	EditedAt time.Time `json:"edited_at" gorm:"default:CURRENT_TIMESTAMP"`
+}
+
+// PostAttachment is one media file of a post, in display order. Images carry
+// resized variants; video and audio carry their duration when it could be read.
+type PostAttachment struct {
+	ID              int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID          uuid.UUID  `json:"post_id" gorm:"type:uuid;not null"`
+	Position        int        `json:"position"`
+	URL             string     `json:"url"`
+	MediaType       string     `json:"media_type"`
+	ContentType     string     `json:"content_type"`
+	SizeBytes       int64      `json:"size_bytes"`
+	Width           *int       `json:"width,omitempty"`
+	Height          *int       `json:"height,omitempty"`
+	DurationSeconds *float64   `json:"duration_seconds,omitempty"`
+	ThumbnailURL    string     `json:"thumbnail_url,omitempty"`
+	MediumURL       string     `json:"medium_url,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"default:CURRENT_TIMESTAMP"`
 }
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Draft statuses. A scheduled draft becomes published, or failed when it can no
+// longer be published, once the scheduler reaches its ScheduledAt.
+const (
+	DraftStatusDraft     = "draft"
+	DraftStatusScheduled = "scheduled"
+	DraftStatusPublished = "published"
+	DraftStatusFailed    = "failed"
+)
+
+// PostDraft is an unpublished post. Its media is uploaded when the draft is
+// saved and moves to post_attachments on publication.
+type PostDraft struct {
+	ID          uuid.UUID        `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+	UserID      uuid.UUID        `json:"user_id" gorm:"type:uuid;not null"`
+	Content     string           `json:"content"`
+	PostType    string           `json:"post_type" gorm:"default:post"`
+	Visibility  string           `json:"visibility" gorm:"default:public"`
+	CommunityID *int             `json:"community_id,omitempty"`
+	Attachments []PostAttachment `json:"attachments" gorm:"serializer:json"`
+	Poll        *DraftPoll       `json:"poll,omitempty" gorm:"serializer:json"`
+	Status      string           `json:"status" gorm:"default:draft"`
+	ScheduledAt *time.Time       `json:"scheduled_at,omitempty"`
+	PostID      *uuid.UUID       `json:"post_id,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	CreatedAt   time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// DraftPoll holds the poll settings of a draft until it is published.
+type DraftPoll struct {
+	MultipleChoice bool       `json:"multiple_choice"`
+	ClosesAt       *time.Time `json:"closes_at,omitempty"`
+	Options        []string   `json:"options"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostRecommendation is a post suggested to a user by one run of the feed
+// recommender. Runs are told apart by ComputedAt, so a feed being scrolled can
+// keep reading the run it started with.
+type PostRecommendation struct {
+	UserID     uuid.UUID `gorm:"column:user_id;type:uuid;primaryKey" json:"user_id"`
+	PostID     uuid.UUID `gorm:"column:post_id;type:uuid;primaryKey" json:"post_id"`
+	ComputedAt time.Time `gorm:"column:computed_at;primaryKey" json:"computed_at"`
+	Score      float64   `gorm:"column:score" json:"score"`
+}
+
+func (PostRecommendation) TableName() string {
+	return "post_recommendations"
+}
@@ -2,8 +2,15 @@ package models
 
 import "github.com/google/uuid"
 
+// Post tag sources
+const (
+	TagSourcePredicted = "predicted"
+	TagSourceHashtag   = "hashtag"
+)
+
 type PostTag struct {
 	ID     int       `gorm:"primaryKey"`
 	PostID uuid.UUID `gorm:"column:post_id"`
 	TagID  int       `gorm:"column:tag_id"`
+	Source string    `gorm:"column:source;default:predicted"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Post view kinds. An impression is a post shown in a list; a view is the
+// post opened on its own.
+const (
+	PostViewImpression = "impression"
+	PostViewView       = "view"
+)
+
+// PostView records that a user saw a post during the window starting at
+// WindowStart. A user counts at most once per post, kind and window.
+type PostView struct {
+	PostID      uuid.UUID `gorm:"column:post_id;type:uuid;primaryKey" json:"post_id"`
+	UserID      uuid.UUID `gorm:"column:user_id;type:uuid;primaryKey" json:"user_id"`
+	Kind        string    `gorm:"column:kind;primaryKey" json:"kind"`
+	WindowStart time.Time `gorm:"column:window_start;primaryKey" json:"window_start"`
+}
+
+func (PostView) TableName() string {
+	return "post_views"
+}
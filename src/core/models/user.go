@@ -27,6 +27,7 @@ type User struct {
 	AuthID                  uuid.UUID `gorm:"column:auth_id;type:uuid;unique" json:"auth_id"`
 	CreatedAt               time.Time `gorm:"column:created_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt               time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	SuspendedUntil          *time.Time `gorm:"column:suspended_until;type:timestamp with time zone" json:"-"`
 }
 
 func (User) TableName() string {
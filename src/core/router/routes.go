@@ -2,13 +2,17 @@ package router
 
 import (
 	"Backend/src/core/middleware"
+	"Backend/src/modules/analytics"
 	"Backend/src/modules/IoT_logs"
 	"Backend/src/modules/authentication"
+	"Backend/src/modules/bookmarks"
 	"Backend/src/modules/communities"
 	connection "Backend/src/modules/connections"
 	"Backend/src/modules/events"
 	"Backend/src/modules/feed"
+	"Backend/src/modules/media"
 	"Backend/src/modules/messages"
+	"Backend/src/modules/moderation"
 	"Backend/src/modules/notifications"
 	"Backend/src/modules/posts"
 	"Backend/src/modules/questions"
@@ -18,6 +22,7 @@ import (
 	"Backend/src/modules/users"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
@@ -25,6 +30,8 @@ import (
 )
 
 func InitialiseAndSetupRoutes(app *fiber.App) {
+	app.Use(middleware.BodyLimit(bodyLimit))
+
 	root := app.Group("/", logger.New())
 
 	root.Get("/ping", func(c *fiber.Ctx) error { return c.SendString("pong") })
@@ -45,6 +52,28 @@ func InitialiseAndSetupRoutes(app *fiber.App) {
 	}
 }
 
+// bodyLimit gives the routes that accept attachments room for a full set,
+// including one maximum-size video, and holds every other route to Fiber's default.
+func bodyLimit(c *fiber.Ctx) int {
+	if isMediaUpload(c) {
+		return 2 * media.MaxSize()
+	}
+	return fiber.DefaultBodyLimit
+}
+
+// isMediaUpload reports whether the request creates a post or saves a draft.
+func isMediaUpload(c *fiber.Ctx) bool {
+	path := strings.TrimSuffix(c.Path(), "/")
+	switch c.Method() {
+	case fiber.MethodPost:
+		return path == "/api/v1/posts/post" || path == "/api/v1/posts/drafts"
+	case fiber.MethodPut:
+		draftID, ok := strings.CutPrefix(path, "/api/v1/posts/drafts/")
+		return ok && draftID != "" && !strings.Contains(draftID, "/")
+	}
+	return false
+}
+
 func setupAPIV1Routes(router fiber.Router) {
 	// Grouped API endpoints
 	authGroup := router.Group("/auth")
@@ -56,6 +85,8 @@ func setupAPIV1Routes(router fiber.Router) {
 	communityGroup :=router.Group("/communities")
 	iotlogsGroup :=router.Group("/iotlogs")
 	notificationsGroup :=router.Group("/notification")
+	bookmarkGroup := router.Group("/bookmarks")
+	moderationGroup := router.Group("/moderation")
 	// messagesGroup := router.Group("/messages")
 
 	// Authentication routes
@@ -79,12 +110,41 @@ func setupAPIV1Routes(router fiber.Router) {
 	userGroup.Get("/college", middleware.Protected(), users.GetAllColleges)
 	userGroup.Get("/search",users.SearchUsers)
 	userGroup.Get("/profile/:id",middleware.Protected(),users.GetProfileByID)
+	userGroup.Post("/:user_id/report", middleware.Protected(), moderation.ReportUser)
 	
 	postGroup.Post("/post", middleware.Protected(), posts.CreatePost)
 	postGroup.Post("/like", middleware.Protected(), posts.CreateLike)
 	postGroup.Post("/comment", middleware.Protected(), posts.CreateComment)
 	postGroup.Get("/:post_id/likes/count", middleware.Protected(), posts.GetLikesCount)
 	postGroup.Post("/share", middleware.Protected(), posts.CreateShare)
+	postGroup.Get("/drafts", middleware.Protected(), posts.GetDrafts)
+	postGroup.Post("/drafts", middleware.Protected(), posts.CreateDraft)
+	postGroup.Get("/drafts/:draft_id", middleware.Protected(), posts.GetDraft)
+	postGroup.Put("/drafts/:draft_id", middleware.Protected(), posts.UpdateDraft)
+	postGroup.Delete("/drafts/:draft_id", middleware.Protected(), posts.DeleteDraft)
+	postGroup.Post("/drafts/:draft_id/publish", middleware.Protected(), posts.PublishDraft)
+	postGroup.Get("/scheduled", middleware.Protected(), posts.GetScheduledPosts)
+	postGroup.Delete("/scheduled/:draft_id", middleware.Protected(), posts.CancelScheduledPost)
+	postGroup.Get("/analytics", middleware.Protected(), analytics.GetAuthorAnalytics)
+	postGroup.Get("/hashtags/trending", middleware.Protected(), posts.GetTrendingHashtags)
+	postGroup.Get("/hashtags/:tag", middleware.Protected(), posts.GetPostsByHashtag)
+	postGroup.Get("/:post_id", middleware.Protected(), posts.GetPost)
+	postGroup.Put("/:post_id", middleware.Protected(), posts.UpdatePost)
+	postGroup.Delete("/:post_id", middleware.Protected(), posts.DeletePost)
+	postGroup.Put("/:post_id/visibility", middleware.Protected(), posts.UpdatePostVisibility)
+	postGroup.Get("/:post_id/analytics", middleware.Protected(), analytics.GetPostAnalytics)
+	postGroup.Get("/:post_id/history", middleware.Protected(), posts.GetPostHistory)
+	postGroup.Get("/:post_id/comments", middleware.Protected(), posts.GetComments)
+	postGroup.Post("/:post_id/repost", middleware.Protected(), posts.Repost)
+	postGroup.Get("/:post_id/reactions", middleware.Protected(), posts.GetReactions)
+	postGroup.Put("/:post_id/reactions", middleware.Protected(), posts.SetReaction)
+	postGroup.Delete("/:post_id/reactions", middleware.Protected(), posts.RemoveReaction)
+	postGroup.Get("/:post_id/poll", middleware.Protected(), posts.GetPoll)
+	postGroup.Post("/:post_id/poll/votes", middleware.Protected(), posts.VotePoll)
+	postGroup.Post("/:post_id/report", middleware.Protected(), moderation.ReportPost)
+	postGroup.Put("/comments/:comment_id", middleware.Protected(), posts.UpdateComment)
+	postGroup.Delete("/comments/:comment_id", middleware.Protected(), posts.DeleteComment)
+	postGroup.Post("/comments/:comment_id/report", middleware.Protected(), moderation.ReportComment)
 
 	eventGroup.Post("/event", middleware.Protected(), events.CreateEvent)
 	eventGroup.Post("/workshop", middleware.Protected(), events.CreateWorkshop)
@@ -102,24 +162,64 @@ func setupAPIV1Routes(router fiber.Router) {
 	questionGroup.Post("/submit", middleware.Protected(), questions.SubmitAnswer)
 
 	communityGroup.Post("/create",middleware.Protected(),communities.CreateCommunity)
+	communityGroup.Post("/invites/:code/accept", middleware.Protected(), communities.AcceptInvite)
+	communityGroup.Get("/recommended", middleware.Protected(), communities.GetRecommendedCommunities)
 	communityGroup.Post("/:id/join",middleware.Protected(),communities.JoinCommunity)
 	communityGroup.Get("/:id",middleware.Protected(), communities.GetCommunityDetails)
     communityGroup.Get("/", middleware.Protected(), communities.GetAllCommunities)
 	communityGroup.Post("/:id/leave", middleware.Protected(), communities.LeaveCommunity)
 	communityGroup.Get("/user/joined", middleware.Protected(), communities.GetUserCommunities)
 	communityGroup.Get("/:id/messages", middleware.Protected(), communities.GetCommunityMessages)
+	communityGroup.Get("/:id/messages/search", middleware.Protected(), communities.SearchCommunityMessages)
+	communityGroup.Get("/messages/search", middleware.Protected(), communities.SearchMessages)
+	communityGroup.Delete("/:id/messages/:message_id", middleware.Protected(), communities.DeleteCommunityMessage)
+	communityGroup.Post("/:id/messages/:message_id/report", middleware.Protected(), moderation.ReportMessage)
+	communityGroup.Get("/:id/posts", middleware.Protected(), communities.GetCommunityPosts)
+	communityGroup.Get("/:id/calendar", middleware.Protected(), communities.GetCommunityCalendar)
+	communityGroup.Get("/:id/members", middleware.Protected(), communities.GetCommunityMembers)
+	communityGroup.Put("/:id/members/:user_id/role", middleware.Protected(), communities.UpdateMemberRole)
+	communityGroup.Delete("/:id/members/:user_id", middleware.Protected(), communities.KickMember)
+	communityGroup.Post("/:id/members/:user_id/mute", middleware.Protected(), communities.MuteMember)
+	communityGroup.Delete("/:id/members/:user_id/mute", middleware.Protected(), communities.UnmuteMember)
+	communityGroup.Get("/:id/bans", middleware.Protected(), communities.GetCommunityBans)
+	communityGroup.Post("/:id/members/:user_id/ban", middleware.Protected(), communities.BanMember)
+	communityGroup.Delete("/:id/bans/:user_id", middleware.Protected(), communities.UnbanMember)
+	communityGroup.Put("/:id", middleware.Protected(), communities.UpdateCommunitySettings)
+	communityGroup.Post("/:id/icon", middleware.Protected(), communities.UploadCommunityIcon)
+	communityGroup.Get("/:id/invites", middleware.Protected(), communities.GetInvites)
+	communityGroup.Post("/:id/invites", middleware.Protected(), communities.CreateInvite)
+	communityGroup.Delete("/:id/invites/:invite_id", middleware.Protected(), communities.RevokeInvite)
+	communityGroup.Get("/:id/join-requests", middleware.Protected(), communities.GetJoinRequests)
+	communityGroup.Post("/:id/join-requests/:request_id/approve", middleware.Protected(), communities.ApproveJoinRequest)
+	communityGroup.Post("/:id/join-requests/:request_id/deny", middleware.Protected(), communities.DenyJoinRequest)
 	// communityGroup.Post("/:id/messages", middleware.Protected(), messages.SendMessage)
 	communityGroup.Get("/:id/messages/ws", func(c *fiber.Ctx) error {
 		log.Println("Request for WebSocket upgrade received")
 		return messages.WebSocketHandler(c)
 	})
-	communityGroup.Get("/:id/messages/ws/conn", websocket.New(messages.WebSocketConnHandler)) 
+	communityGroup.Get("/:id/messages/ws/conn", middleware.ProtectedSocket(), websocket.New(messages.WebSocketConnHandler))
 	
 	iotlogsGroup.Post("/",iotlogs.CreateIotLog)
 	iotlogsGroup.Get("/",middleware.Protected(),iotlogs.GetIotLogs)
 
-	notificationsGroup.Get("/ws",websocket.New(notifications.NotificationWebSocketHandler))
+	notificationsGroup.Get("/ws", middleware.ProtectedSocket(), websocket.New(notifications.NotificationWebSocketHandler))
     notificationsGroup.Get("/",middleware.Protected(),messages.GetNotifications)
+	// Bookmark routes
+	bookmarkGroup.Get("/", middleware.Protected(), bookmarks.GetBookmarks)
+	bookmarkGroup.Post("/", middleware.Protected(), bookmarks.AddBookmark)
+	bookmarkGroup.Get("/collections", middleware.Protected(), bookmarks.GetCollections)
+	bookmarkGroup.Post("/collections", middleware.Protected(), bookmarks.CreateCollection)
+	bookmarkGroup.Put("/collections/:id", middleware.Protected(), bookmarks.RenameCollection)
+	bookmarkGroup.Delete("/collections/:id", middleware.Protected(), bookmarks.DeleteCollection)
+	bookmarkGroup.Delete("/:item_type/:item_id", middleware.Protected(), bookmarks.RemoveBookmark)
+
+	// Moderation routes
+	moderationGroup.Get("/cases", middleware.Protected(), moderation.GetCases)
+	moderationGroup.Get("/cases/:case_id", middleware.Protected(), moderation.GetCase)
+	moderationGroup.Put("/cases/:case_id/assignee", middleware.Protected(), moderation.AssignCase)
+	moderationGroup.Post("/cases/:case_id/actions", middleware.Protected(), moderation.TakeAction)
+	moderationGroup.Get("/audit", middleware.Protected(), moderation.GetAuditLog)
+
 	// // Feed routes
 	feedGroup.Get("/", middleware.Protected(), feed.FetchFeed)
 	// feedGroup.Post("/", middleware.Protected(), feed.CreatePost)
@@ -0,0 +1,262 @@
+package analytics
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+)
+
+// PostStats is the lifetime performance of one post. NewFollowers counts the
+// author's followers who saw the post before following.
+type PostStats struct {
+	PostID        uuid.UUID `json:"post_id"`
+	Excerpt       string    `json:"excerpt"`
+	Visibility    string    `json:"visibility"`
+	CreatedAt     time.Time `json:"created_at"`
+	Impressions   int64     `json:"impressions"`
+	Views         int64     `json:"views"`
+	UniqueViewers int64     `json:"unique_viewers"`
+	Reactions     int64     `json:"reactions"`
+	Comments      int64     `json:"comments"`
+	Shares        int64     `json:"shares"`
+	NewFollowers  int64     `json:"new_followers"`
+}
+
+// DailyStats is the activity on one day. Unique viewers are distinct within the day.
+type DailyStats struct {
+	Day           time.Time `json:"day"`
+	Impressions   int64     `json:"impressions"`
+	Views         int64     `json:"views"`
+	UniqueViewers int64     `json:"unique_viewers"`
+	Reactions     int64     `json:"reactions"`
+	Comments      int64     `json:"comments"`
+	Shares        int64     `json:"shares"`
+	NewFollowers  int64     `json:"new_followers"`
+}
+
+// Totals sums a series. Unique viewers are distinct over the whole period.
+type Totals struct {
+	Impressions   int64 `json:"impressions"`
+	Views         int64 `json:"views"`
+	UniqueViewers int64 `json:"unique_viewers"`
+	Reactions     int64 `json:"reactions"`
+	Comments      int64 `json:"comments"`
+	Shares        int64 `json:"shares"`
+	NewFollowers  int64 `json:"new_followers"`
+}
+
+// postStatsColumns selects PostStats for the posts in the FROM clause.
+const postStatsColumns = `
+	posts.id AS post_id, LEFT(posts.content, 140) AS excerpt, posts.visibility, posts.created_at,
+	(SELECT COUNT(*) FROM post_views WHERE post_views.post_id = posts.id AND post_views.kind = 'impression') AS impressions,
+	(SELECT COUNT(*) FROM post_views WHERE post_views.post_id = posts.id AND post_views.kind = 'view') AS views,
+	(SELECT COUNT(DISTINCT post_views.user_id) FROM post_views WHERE post_views.post_id = posts.id AND post_views.kind = 'view') AS unique_viewers,
+	(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id) AS reactions,
+	(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted_at IS NULL AND comments.hidden_at IS NULL) AS comments,
+	(SELECT COUNT(*) FROM shares WHERE shares.post_id = posts.id) AS shares,
+	(SELECT COUNT(*) FROM connections WHERE connections.connection_id = posts.user_id AND EXISTS (
+		SELECT 1 FROM post_views WHERE post_views.post_id = posts.id AND post_views.user_id = connections.user_id
+		AND post_views.window_start <= connections.created_at)) AS new_followers`
+
+// GetAuthorAnalytics summarises how the caller's posts performed over the
+// last ?days (default 30): totals, a daily series including follower growth,
+// and a page of posts with their lifetime stats, ordered by ?sort (recent,
+// impressions, views, reactions or comments).
+func GetAuthorAnalytics(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch analytics")
+	}
+	days := parseDays(c)
+	limit, offset := helpers.ParsePagination(c, 10, 50)
+
+	var orderBy string
+	switch c.Query("sort", "recent") {
+	case "recent":
+		orderBy = "posts.created_at DESC, posts.id DESC"
+	case "impressions":
+		orderBy = "impressions DESC, posts.created_at DESC"
+	case "views":
+		orderBy = "views DESC, posts.created_at DESC"
+	case "reactions":
+		orderBy = "reactions DESC, posts.created_at DESC"
+	case "comments":
+		orderBy = "comments DESC, posts.created_at DESC"
+	default:
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Sort must be one of recent, impressions, views, reactions or comments", nil)
+	}
+
+	authorPosts := db.Table("posts").Select("id").Where("user_id = ? AND deleted_at IS NULL", userID)
+	newFollowers := db.Table("connections").Where("connection_id = ?", userID)
+
+	series, totals, err := dailySeries(db, authorPosts, newFollowers, days, time.Now())
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch analytics", err)
+	}
+
+	var followers int64
+	if err := db.Table("connections").Where("connection_id = ?", userID).Count(&followers).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to count followers", err)
+	}
+
+	var total int64
+	if err := db.Table("posts").Where("user_id = ? AND deleted_at IS NULL", userID).Count(&total).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to count posts", err)
+	}
+	posts := []PostStats{}
+	if err := db.Table("posts").
+		Select(postStatsColumns).
+		Where("posts.user_id = ? AND posts.deleted_at IS NULL", userID).
+		Order(orderBy).
+		Limit(limit).
+		Offset(offset).
+		Scan(&posts).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch post stats", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Analytics fetched successfully", fiber.Map{
+		"days":      days,
+		"followers": followers,
+		"totals":    totals,
+		"series":    series,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+		"posts":     posts,
+	})
+}
+
+// GetPostAnalytics returns the lifetime stats of one of the caller's posts and
+// its daily series over the last ?days (default 30).
+func GetPostAnalytics(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch analytics")
+	}
+	postID, err := uuid.Parse(c.Params("post_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid post ID format", err)
+	}
+	days := parseDays(c)
+
+	var post models.Post
+	if err := db.Where("id = ? AND deleted_at IS NULL", postID).First(&post).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return helpers.HandleError(c, fiber.StatusNotFound, "Post not found", nil)
+		}
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch post", err)
+	}
+	if post.UserID != userID {
+		return helpers.HandleError(c, fiber.StatusForbidden, "Only the author can view a post's analytics", nil)
+	}
+
+	var stats PostStats
+	if err := db.Table("posts").Select(postStatsColumns).Where("posts.id = ?", postID).Scan(&stats).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch post stats", err)
+	}
+
+	newFollowers := db.Table("connections").
+		Where("connection_id = ?", userID).
+		Where(`EXISTS (SELECT 1 FROM post_views WHERE post_views.post_id = ? AND post_views.user_id = connections.user_id
+			AND post_views.window_start <= connections.created_at)`, postID)
+	series, totals, err := dailySeries(db, []uuid.UUID{postID}, newFollowers, days, time.Now())
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch analytics", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Post analytics fetched successfully", fiber.Map{
+		"days":     days,
+		"lifetime": stats,
+		"totals":   totals,
+		"series":   series,
+	})
+}
+
+// dailySeries returns the activity on postIDs (a list or a subquery) for each
+// of the last days days up to now, and its totals. newFollowers selects the
+// connections counted as follower growth.
+func dailySeries(db *gorm.DB, postIDs interface{}, newFollowers *gorm.DB, days int, now time.Time) ([]DailyStats, Totals, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	since := today.AddDate(0, 0, 1-days)
+
+	series := []DailyStats{}
+	if err := db.Raw(`
+		SELECT days.day,
+		       COALESCE(v.impressions, 0) AS impressions, COALESCE(v.views, 0) AS views,
+		       COALESCE(v.unique_viewers, 0) AS unique_viewers, COALESCE(l.reactions, 0) AS reactions,
+		       COALESCE(cm.comments, 0) AS comments, COALESCE(s.shares, 0) AS shares,
+		       COALESCE(f.new_followers, 0) AS new_followers
+		FROM (SELECT CAST(generate_series(CAST(? AS date), CAST(? AS date), interval '1 day') AS date) AS day) AS days
+		LEFT JOIN (
+			SELECT CAST(window_start AS date) AS day,
+			       COUNT(*) FILTER (WHERE kind = 'impression') AS impressions,
+			       COUNT(*) FILTER (WHERE kind = 'view') AS views,
+			       COUNT(DISTINCT user_id) FILTER (WHERE kind = 'view') AS unique_viewers
+			FROM post_views WHERE post_id IN (?) AND window_start >= ? GROUP BY 1
+		) AS v ON v.day = days.day
+		LEFT JOIN (
+			SELECT CAST(created_at AS date) AS day, COUNT(*) AS reactions
+			FROM likes WHERE post_id IN (?) AND created_at >= ? GROUP BY 1
+		) AS l ON l.day = days.day
+		LEFT JOIN (
+			SELECT CAST(created_at AS date) AS day, COUNT(*) AS comments
+			FROM comments WHERE post_id IN (?) AND created_at >= ? AND deleted_at IS NULL AND hidden_at IS NULL GROUP BY 1
+		) AS cm ON cm.day = days.day
+		LEFT JOIN (
+			SELECT CAST(shared_at AS date) AS day, COUNT(*) AS shares
+			FROM shares WHERE post_id IN (?) AND shared_at >= ? GROUP BY 1
+		) AS s ON s.day = days.day
+		LEFT JOIN (?) AS f ON f.day = days.day
+		ORDER BY days.day
+	`, since, today,
+		postIDs, since,
+		postIDs, since,
+		postIDs, since,
+		postIDs, since,
+		newFollowers.Select("CAST(connections.created_at AS date) AS day, COUNT(*) AS new_followers").
+			Where("connections.created_at >= ?", since).Group("CAST(connections.created_at AS date)"),
+	).Scan(&series).Error; err != nil {
+		return nil, Totals{}, err
+	}
+
+	var totals Totals
+	for _, day := range series {
+		totals.Impressions += day.Impressions
+		totals.Views += day.Views
+		totals.Reactions += day.Reactions
+		totals.Comments += day.Comments
+		totals.Shares += day.Shares
+		totals.NewFollowers += day.NewFollowers
+	}
+	if err := db.Table("post_views").
+		Where("post_id IN (?) AND kind = ? AND window_start >= ?", postIDs, models.PostViewView, since).
+		Select("COUNT(DISTINCT user_id)").
+		Scan(&totals.UniqueViewers).Error; err != nil {
+		return nil, Totals{}, err
+	}
+	return series, totals, nil
+}
+
+// parseDays reads ?days, defaulting out-of-range values.
+func parseDays(c *fiber.Ctx) int {
+	days := c.QueryInt("days", defaultAnalyticsDays)
+	if days <= 0 || days > maxAnalyticsDays {
+		days = defaultAnalyticsDays
+	}
+	return days
+}
@@ -0,0 +1,156 @@
+package analytics
+
+import (
+	"Backend/src/core/config"
+	"Backend/src/core/database"
+	"Backend/src/core/models"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultViewWindow    = 30 * time.Minute
+	defaultFlushInterval = 10 * time.Second
+	// Pending views are flushed early once this many are buffered
+	maxPendingViews = 5000
+	// Rows per INSERT, keeping well under the bind parameter limit
+	viewInsertBatch = 500
+)
+
+type viewKey struct {
+	postID uuid.UUID
+	userID uuid.UUID
+	kind   string
+	window time.Time
+}
+
+// Views are buffered in memory and written in batches by FlushViews. The
+// buffer is a set, so repeat sightings within a window cost nothing.
+var (
+	pendingMu    sync.Mutex
+	pendingViews = make(map[viewKey]struct{})
+	flushNow     = make(chan struct{}, 1)
+)
+
+// viewWindow is how long a user's repeat sightings of a post count once,
+// from ANALYTICS_VIEW_WINDOW_MINUTES.
+func viewWindow() time.Duration {
+	if value := config.Config("ANALYTICS_VIEW_WINDOW_MINUTES"); value != "" {
+		if minutes, err := strconv.Atoi(value); err == nil && minutes > 0 {
+			return time.Duration(minutes) * time.Minute
+		}
+	}
+	return defaultViewWindow
+}
+
+// flushInterval is how often buffered views are written, from ANALYTICS_FLUSH_SECONDS.
+func flushInterval() time.Duration {
+	if value := config.Config("ANALYTICS_FLUSH_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultFlushInterval
+}
+
+// RecordImpressions notes that the posts were shown to the viewer in a list.
+func RecordImpressions(viewerID uuid.UUID, postIDs []uuid.UUID) {
+	record(viewerID, postIDs, models.PostViewImpression)
+}
+
+// RecordView notes that the viewer opened the post.
+func RecordView(viewerID, postID uuid.UUID) {
+	record(viewerID, []uuid.UUID{postID}, models.PostViewView)
+}
+
+func record(viewerID uuid.UUID, postIDs []uuid.UUID, kind string) {
+	if len(postIDs) == 0 {
+		return
+	}
+	window := time.Now().Truncate(viewWindow())
+
+	pendingMu.Lock()
+	for _, postID := range postIDs {
+		pendingViews[viewKey{postID: postID, userID: viewerID, kind: kind, window: window}] = struct{}{}
+	}
+	full := len(pendingViews) >= maxPendingViews
+	pendingMu.Unlock()
+
+	if full {
+		select {
+		case flushNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// FlushViews writes the buffered views every ANALYTICS_FLUSH_SECONDS, or
+// sooner when the buffer fills up.
+func FlushViews() {
+	ticker := time.NewTicker(flushInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-flushNow:
+		}
+		if err := flushPendingViews(database.DB); err != nil {
+			log.Printf("Error writing post views: %v\n", err)
+		}
+	}
+}
+
+// flushPendingViews takes the buffer and inserts it. Views of one's own posts
+// and of posts deleted in the meantime are dropped, as are views already
+// recorded for the window. Views in a failed batch are lost; analytics are
+// not worth holding memory for an unavailable database.
+func flushPendingViews(db *gorm.DB) error {
+	pendingMu.Lock()
+	if len(pendingViews) == 0 {
+		pendingMu.Unlock()
+		return nil
+	}
+	views := pendingViews
+	pendingViews = make(map[viewKey]struct{})
+	pendingMu.Unlock()
+
+	keys := make([]viewKey, 0, len(views))
+	for key := range views {
+		keys = append(keys, key)
+	}
+
+	var firstErr error
+	for start := 0; start < len(keys); start += viewInsertBatch {
+		end := start + viewInsertBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := insertViews(db, keys[start:end]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func insertViews(db *gorm.DB, keys []viewKey) error {
+	rows := make([]string, len(keys))
+	args := make([]interface{}, 0, 4*len(keys))
+	for i, key := range keys {
+		rows[i] = "(CAST(? AS uuid), CAST(? AS uuid), ?, CAST(? AS timestamp))"
+		args = append(args, key.postID, key.userID, key.kind, key.window)
+	}
+	return db.Exec(`
+		INSERT INTO post_views (post_id, user_id, kind, window_start)
+		SELECT v.post_id, v.user_id, v.kind, v.window_start
+		FROM (VALUES `+strings.Join(rows, ", ")+`) AS v(post_id, user_id, kind, window_start)
+		JOIN posts ON posts.id = v.post_id
+		WHERE posts.user_id <> v.user_id AND posts.deleted_at IS NULL
+		ON CONFLICT (post_id, user_id, kind, window_start) DO NOTHING
+	`, args...).Error
+}
@@ -125,6 +125,9 @@ func SignIn(c *fiber.Ctx) error {
 		fmt.Println("Error fetching user:", err)
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch user details", err)
 	}
+	if user.SuspendedUntil != nil && user.SuspendedUntil.After(time.Now()) {
+		return helpers.HandleError(c, fiber.StatusForbidden, "Your account is suspended until "+user.SuspendedUntil.UTC().Format(time.RFC1123), nil)
+	}
 
 	token, err := issueJwtToken(fetchedUser.ID.String(),
 		user.ID.String(),
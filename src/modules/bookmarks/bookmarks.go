@@ -0,0 +1,263 @@
+package bookmarks
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/events"
+	"Backend/src/modules/feed"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// itemTables maps each bookmarkable item type to the table holding it.
+var itemTables = map[string]string{
+	models.BookmarkItemPost:     "posts",
+	models.BookmarkItemEvent:    "events",
+	models.BookmarkItemWorkshop: "workshops",
+	models.BookmarkItemProject:  "projects",
+}
+
+// BookmarkItem is a bookmark together with the saved item. Item is nil when
+// the item is no longer visible to the caller.
+type BookmarkItem struct {
+	models.Bookmark
+	Item interface{} `json:"item"`
+}
+
+// AddBookmark saves an item for the caller, or moves an existing bookmark to
+// another collection.
+func AddBookmark(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	var req struct {
+		ItemType     string `json:"item_type"`
+		ItemID       string `json:"item_id"`
+		CollectionID *int   `json:"collection_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+	}
+	if req.ItemType == "" {
+		req.ItemType = models.BookmarkItemPost
+	}
+	table, ok := itemTables[req.ItemType]
+	if !ok {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Item type must be post, event, workshop or project", nil)
+	}
+	itemID, err := uuid.Parse(req.ItemID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid item ID format", err)
+	}
+
+	exists, err := itemExists(db, userID, req.ItemType, table, itemID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to look up item", err)
+	}
+	if !exists {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Item not found", nil)
+	}
+
+	if req.CollectionID != nil {
+		if _, err := loadCollection(db, userID, *req.CollectionID); err != nil {
+			return helpers.HandleFiberError(c, err, "Failed to fetch collection")
+		}
+	}
+
+	bookmark := models.Bookmark{
+		UserID:       userID,
+		ItemType:     req.ItemType,
+		ItemID:       itemID,
+		CollectionID: req.CollectionID,
+		CreatedAt:    time.Now(),
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "item_type"}, {Name: "item_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"collection_id"}),
+	}).Create(&bookmark).Error; err != nil {
+		log.Printf("Error saving bookmark: %v\n", err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to save bookmark", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Bookmark saved successfully", bookmark)
+}
+
+// RemoveBookmark deletes the caller's bookmark of an item.
+func RemoveBookmark(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	itemType := c.Params("item_type")
+	if _, ok := itemTables[itemType]; !ok {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Item type must be post, event, workshop or project", nil)
+	}
+	itemID, err := uuid.Parse(c.Params("item_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid item ID format", err)
+	}
+
+	result := db.Where("user_id = ? AND item_type = ? AND item_id = ?", userID, itemType, itemID).Delete(&models.Bookmark{})
+	if result.Error != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to remove bookmark", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Bookmark not found", nil)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Bookmark removed successfully", nil)
+}
+
+// GetBookmarks lists the caller's bookmarks, newest first, optionally filtered
+// by ?item_type and ?collection_id ("none" for uncollected bookmarks).
+func GetBookmarks(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	limit, offset := helpers.ParsePagination(c, 20, 100)
+
+	query := whereVisible(db, db.Model(&models.Bookmark{}).Where("user_id = ?", userID), userID)
+	if itemType := c.Query("item_type"); itemType != "" {
+		if _, ok := itemTables[itemType]; !ok {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Item type must be post, event, workshop or project", nil)
+		}
+		query = query.Where("item_type = ?", itemType)
+	}
+	switch collection := c.Query("collection_id"); collection {
+	case "":
+	case "none":
+		query = query.Where("collection_id IS NULL")
+	default:
+		collectionID := c.QueryInt("collection_id")
+		if _, err := loadCollection(db, userID, collectionID); err != nil {
+			return helpers.HandleFiberError(c, err, "Failed to fetch collection")
+		}
+		query = query.Where("collection_id = ?", collectionID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to count bookmarks", err)
+	}
+
+	var bookmarks []models.Bookmark
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&bookmarks).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch bookmarks", err)
+	}
+
+	items, err := hydrateBookmarks(db, userID, bookmarks)
+	if err != nil {
+		log.Printf("Error loading bookmarked items: %v\n", err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to load bookmarked items", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Bookmarks fetched successfully", fiber.Map{
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+		"bookmarks": items,
+	})
+}
+
+// hydrateBookmarks loads the saved items of a page of bookmarks, one query per item type.
+func hydrateBookmarks(db *gorm.DB, userID uuid.UUID, bookmarks []models.Bookmark) ([]BookmarkItem, error) {
+	idsByType := make(map[string][]uuid.UUID)
+	for _, bookmark := range bookmarks {
+		idsByType[bookmark.ItemType] = append(idsByType[bookmark.ItemType], bookmark.ItemID)
+	}
+
+	found := make(map[string]interface{})
+	key := func(itemType string, id uuid.UUID) string { return itemType + ":" + id.String() }
+
+	if ids := idsByType[models.BookmarkItemPost]; len(ids) > 0 {
+		var posts []models.Post
+		if err := feed.PostQuery(db, userID).Where("posts.id IN (?)", ids).Find(&posts).Error; err != nil {
+			return nil, err
+		}
+		feedPosts := feed.BuildFeedPosts(posts)
+		if err := feed.ApplyViewerState(userID, feedPosts); err != nil {
+			return nil, err
+		}
+		for _, post := range feedPosts {
+			found[key(models.BookmarkItemPost, uuid.MustParse(post.ID))] = post
+		}
+	}
+	if ids := idsByType[models.BookmarkItemEvent]; len(ids) > 0 {
+		var eventRows []models.Event
+		if err := db.Where("id IN (?)", ids).Find(&eventRows).Error; err != nil {
+			return nil, err
+		}
+		for _, event := range eventRows {
+			found[key(models.BookmarkItemEvent, event.ID)] = event
+		}
+	}
+	if ids := idsByType[models.BookmarkItemWorkshop]; len(ids) > 0 {
+		var workshops []models.Workshop
+		if err := db.Where("id IN (?)", ids).Find(&workshops).Error; err != nil {
+			return nil, err
+		}
+		for _, workshop := range workshops {
+			found[key(models.BookmarkItemWorkshop, workshop.ID)] = workshop
+		}
+	}
+	if ids := idsByType[models.BookmarkItemProject]; len(ids) > 0 {
+		var projects []models.Project
+		if err := db.Where("id IN (?)", ids).Find(&projects).Error; err != nil {
+			return nil, err
+		}
+		for _, project := range projects {
+			found[key(models.BookmarkItemProject, project.ID)] = project
+		}
+	}
+
+	items := make([]BookmarkItem, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		items[i] = BookmarkItem{Bookmark: bookmark, Item: found[key(bookmark.ItemType, bookmark.ItemID)]}
+	}
+	return items, nil
+}
+
+// itemExists reports whether the item exists and, for posts, events and
+// workshops, is visible to the user.
+func itemExists(db *gorm.DB, userID uuid.UUID, itemType, table string, itemID uuid.UUID) (bool, error) {
+	query := db.Table(table).Where(fmt.Sprintf("%s.id = ?", table), itemID)
+	switch itemType {
+	case models.BookmarkItemPost:
+		query = query.Where(feed.VisiblePostsClause, userID)
+	case models.BookmarkItemEvent, models.BookmarkItemWorkshop:
+		query = query.Where(events.VisibleCommunityItemsClause, userID)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// whereVisible drops bookmarks of items the user can no longer see: posts
+// hidden from them, and events and workshops of communities they are not in.
+func whereVisible(db, query *gorm.DB, userID uuid.UUID) *gorm.DB {
+	visiblePosts := db.Table("posts").Select("posts.id").Where(feed.VisiblePostsClause, userID)
+	visibleEvents := db.Table("events").Select("id").Where(events.VisibleCommunityItemsClause, userID)
+	visibleWorkshops := db.Table("workshops").Select("id").Where(events.VisibleCommunityItemsClause, userID)
+	return query.
+		Where("(bookmarks.item_type <> ? OR bookmarks.item_id IN (?))", models.BookmarkItemPost, visiblePosts).
+		Where("(bookmarks.item_type <> ? OR bookmarks.item_id IN (?))", models.BookmarkItemEvent, visibleEvents).
+		Where("(bookmarks.item_type <> ? OR bookmarks.item_id IN (?))", models.BookmarkItemWorkshop, visibleWorkshops)
+}
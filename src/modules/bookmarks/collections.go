@@ -0,0 +1,170 @@
+package bookmarks
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxCollectionNameLength = 100
+
+// CollectionSummary is a collection with the number of bookmarks filed in it.
+type CollectionSummary struct {
+	models.BookmarkCollection
+	ItemCount int `json:"item_count"`
+}
+
+// GetCollections lists the caller's collections alphabetically.
+func GetCollections(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	// Counts agree with GetBookmarks: items the caller can no longer see are left out
+	visible := whereVisible(db, db.Table("bookmarks").
+		Select("bookmarks.id, bookmarks.collection_id").
+		Where("bookmarks.user_id = ?", userID), userID)
+
+	collections := []CollectionSummary{}
+	if err := db.Table("bookmark_collections").
+		Select("bookmark_collections.*, COUNT(bookmarks.id) AS item_count").
+		Joins("LEFT JOIN (?) AS bookmarks ON bookmarks.collection_id = bookmark_collections.id", visible).
+		Where("bookmark_collections.user_id = ?", userID).
+		Group("bookmark_collections.id").
+		Order("bookmark_collections.name ASC").
+		Scan(&collections).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch collections", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Collections fetched successfully", collections)
+}
+
+// CreateCollection adds a named collection for the caller.
+func CreateCollection(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	name, err := parseCollectionName(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch collection")
+	}
+
+	collection := models.BookmarkCollection{UserID: userID, Name: name}
+	if err := db.Create(&collection).Error; err != nil {
+		if isUniqueViolation(err) {
+			return helpers.HandleError(c, fiber.StatusConflict, "You already have a collection with this name", err)
+		}
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create collection", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Collection created successfully", collection)
+}
+
+// RenameCollection changes the name of one of the caller's collections.
+func RenameCollection(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	collectionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid collection ID format", err)
+	}
+	collection, err := loadCollection(db, userID, collectionID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch collection")
+	}
+
+	name, err := parseCollectionName(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch collection")
+	}
+
+	if err := db.Model(collection).Update("name", name).Error; err != nil {
+		if isUniqueViolation(err) {
+			return helpers.HandleError(c, fiber.StatusConflict, "You already have a collection with this name", err)
+		}
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to rename collection", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Collection renamed successfully", collection)
+}
+
+// DeleteCollection removes a collection; its bookmarks are kept but become uncollected.
+func DeleteCollection(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	collectionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid collection ID format", err)
+	}
+	collection, err := loadCollection(db, userID, collectionID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch collection")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Bookmark{}).Where("collection_id = ?", collection.ID).Update("collection_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(collection).Error
+	})
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to delete collection", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Collection deleted successfully", nil)
+}
+
+// loadCollection returns the user's collection.
+func loadCollection(db *gorm.DB, userID uuid.UUID, collectionID int) (*models.BookmarkCollection, error) {
+	var collection models.BookmarkCollection
+	err := db.Where("id = ? AND user_id = ?", collectionID, userID).First(&collection).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Collection not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+func parseCollectionName(c *fiber.Ctx) (string, error) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid request payload")
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > maxCollectionNameLength {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Collection name must be between 1 and 100 characters")
+	}
+	return name, nil
+}
+
+func isUniqueViolation(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "SQLSTATE 23505")
+}
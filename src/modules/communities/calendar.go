@@ -0,0 +1,100 @@
+package communities
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	calendarDefaultDays = 90
+	calendarMaxItems    = 200
+)
+
+// CalendarItem is an upcoming event or workshop scheduled in a community.
+type CalendarItem struct {
+	ID          uuid.UUID `json:"id"`
+	Type        string    `json:"type"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Date        time.Time `json:"date"`
+	Location    string    `json:"location"`
+	Media       string    `json:"media"`
+	Status      string    `json:"status"`
+}
+
+// GetCommunityCalendar lists the community's events and workshops between
+// ?from and ?to (YYYY-MM-DD), defaulting to the next 90 days.
+func GetCommunityCalendar(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	communityID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid community ID format", err)
+	}
+
+	var community models.Community
+	if err := db.First(&community, communityID).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Community not found", err)
+	}
+
+	if community.Visibility != models.CommunityVisibilityPublic {
+		member, err := isMember(db, communityID, userID)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to verify membership", err)
+		}
+		if !member {
+			return helpers.HandleError(c, fiber.StatusForbidden, "You are not a member of this community", nil)
+		}
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	from, to := today, today.AddDate(0, 0, calendarDefaultDays)
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid from date, expected YYYY-MM-DD", err)
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse("2006-01-02", value); err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid to date, expected YYYY-MM-DD", err)
+		}
+	}
+	if to.Before(from) {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "to must not be before from", nil)
+	}
+
+	// to is inclusive, so match anything scheduled before the following midnight
+	end := to.AddDate(0, 0, 1)
+	query := `
+		SELECT * FROM (
+			SELECT id, 'event' AS type, title, description, date, location, media, status::text
+			FROM events WHERE community_id = ? AND date >= ? AND date < ?
+			UNION ALL
+			SELECT id, 'workshop' AS type, title, description, date, location, media, status::text
+			FROM workshops WHERE community_id = ? AND date >= ? AND date < ?
+		) AS calendar
+		ORDER BY date ASC, title ASC
+		LIMIT ?
+	`
+	items := []CalendarItem{}
+	if err := db.Raw(query, communityID, from, end, communityID, from, end, calendarMaxItems).Scan(&items).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community calendar", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Community calendar fetched successfully", fiber.Map{
+		"from":  from.Format("2006-01-02"),
+		"to":    to.Format("2006-01-02"),
+		"items": items,
+	})
+}
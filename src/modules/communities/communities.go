@@ -19,8 +19,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-
-	// "gorm.io/gorm"
+	"gorm.io/gorm"
 )
 
 func CreateCommunity(c *fiber.Ctx) error {
@@ -44,12 +43,37 @@ func CreateCommunity(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid input data", err)
 	}
 
+	if body.Visibility == "" {
+		body.Visibility = models.CommunityVisibilityPublic
+	}
+	if !isValidVisibility(body.Visibility) {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Visibility must be one of public, request or invite", nil)
+	}
+
+	body.CreatedBy = userID
 	body.CreatedAt = time.Now()
 
-	if result := db.Create(&body); result.Error != nil {
-		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create community", result.Error)
+	// The creator becomes the community's owner in the same transaction
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&body).Error; err != nil {
+			return err
+		}
+		owner := models.CommunityMember{
+			UserID:      userID,
+			CommunityID: body.ID,
+			Role:        models.CommunityRoleOwner,
+		}
+		if err := tx.Create(&owner).Error; err != nil {
+			return err
+		}
+		return setCommunityTags(tx, body.ID, body.Tags)
+	})
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create community", err)
 	}
 
+	body.Tags = normalizeTags(body.Tags)
+
 	return helpers.HandleSuccess(c, fiber.StatusCreated, "Community created successfully", body)
 }
 
@@ -82,9 +106,41 @@ func JoinCommunity(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusConflict, "User is already a member", nil)
 	}
 
+	// Banned users cannot rejoin
+	banned, err := isBanned(db, communityID, userUUID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to check ban status", err)
+	}
+	if banned {
+		return helpers.HandleError(c, fiber.StatusForbidden, "You are banned from this community", nil)
+	}
+
+	var community models.Community
+	if err := db.First(&community, communityID).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Community not found", err)
+	}
+
+	switch community.Visibility {
+	case models.CommunityVisibilityInvite:
+		return helpers.HandleError(c, fiber.StatusForbidden, "This community can only be joined with an invite", nil)
+	case models.CommunityVisibilityRequest:
+		// The note to the admins is optional, so an empty body is not an error
+		var input struct {
+			Message string `json:"message"`
+		}
+		_ = c.BodyParser(&input)
+
+		joinRequest, err := createJoinRequest(db, communityID, userUUID, input.Message)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to request to join the community", err)
+		}
+		return helpers.HandleSuccess(c, fiber.StatusAccepted, "Join request submitted successfully", joinRequest)
+	}
+
 	// Create new membership record
 	communityMember.UserID = userUUID
 	communityMember.CommunityID = communityID
+	communityMember.Role = models.CommunityRoleMember
 	if err := db.Create(&communityMember).Error; err != nil {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to join community", err)
 	}
@@ -92,27 +148,50 @@ func JoinCommunity(c *fiber.Ctx) error {
 	return helpers.HandleSuccess(c, fiber.StatusOK, "Successfully joined the community", communityMember)
 }
 
+// GetCommunityDetails returns a community with its stats and tags. Invite-only
+// communities are hidden from discovery, so they are only shown to members,
+// to users with a pending request to join and to callers passing a valid
+// ?invite code; everyone else gets 404 as if the community did not exist.
 func GetCommunityDetails(c *fiber.Ctx) error {
 	db := database.DB
-	communityID := c.Params("id")
 
-	var community models.Community
-	if err := db.First(&community, communityID).Error; err != nil {
-		return helpers.HandleError(c, fiber.StatusNotFound, "Community not found", err)
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
 	}
 
-	return helpers.HandleSuccess(c, fiber.StatusOK, "Community details fetched successfully", community)
-}
-
-func GetAllCommunities(c *fiber.Ctx) error {
-	db := database.DB
+	communityID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid community ID format", err)
+	}
 
-	var communities []models.Community
-	if err := db.Find(&communities).Error; err != nil {
-		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch communities", err)
+	var communities []CommunityListing
+	query := `
+		SELECT c.*, COALESCE(mc.member_count, 0) AS member_count,
+		       COALESCE(ma.recent_messages, 0) AS recent_messages, ma.last_activity_at,
+		       EXISTS (SELECT 1 FROM community_members m WHERE m.community_id = c.id AND m.user_id = ?) AS is_member
+		FROM communities c
+	` + communityStatsJoins + " WHERE c.id = ?"
+	if err := db.Raw(query, userID, communityID).Scan(&communities).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community", err)
+	}
+	if len(communities) == 0 {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Community not found", nil)
+	}
+	if !communities[0].IsMember && communities[0].Visibility == models.CommunityVisibilityInvite {
+		invited, err := isInvited(db, communityID, userID, c.Query("invite"))
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community", err)
+		}
+		if !invited {
+			return helpers.HandleError(c, fiber.StatusNotFound, "Community not found", nil)
+		}
+	}
+	if err := attachCommunityTags(db, communities); err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community tags", err)
 	}
 
-	return helpers.HandleSuccess(c, fiber.StatusOK, "Communities fetched successfully", communities)
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Community details fetched successfully", communities[0])
 }
 
 func LeaveCommunity(c *fiber.Ctx) error {
@@ -136,6 +215,10 @@ func LeaveCommunity(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusNotFound, "User not a member of the community", err)
 	}
 
+	if communityMember.Role == models.CommunityRoleOwner {
+		return helpers.HandleError(c, fiber.StatusConflict, "The owner cannot leave the community", nil)
+	}
+
 	if err := db.Delete(&communityMember).Error; err != nil {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to leave the community", err)
 	}
@@ -174,7 +257,24 @@ func GetUserCommunities(c *fiber.Ctx) error {
 
 func GetCommunityMessages(c *fiber.Ctx) error {
     db := database.DB
-    communityID := c.Params("id")
+
+    userID, err := helpers.CurrentUserID(c)
+    if err != nil {
+        return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+    }
+
+    communityID, err := strconv.Atoi(c.Params("id"))
+    if err != nil {
+        return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid community ID format", err)
+    }
+
+    member, err := isMember(db, communityID, userID)
+    if err != nil {
+        return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to verify membership", err)
+    }
+    if !member {
+        return helpers.HandleError(c, fiber.StatusForbidden, "You are not a member of this community", nil)
+    }
 
     type MessageWithUser struct {
 		ID          int       `json:"id"`
@@ -187,10 +287,10 @@ func GetCommunityMessages(c *fiber.Ctx) error {
 
     var messages []MessageWithUser
     query := `
-        SELECT m.id AS message_id, m.user_id, u.username, m.message, m.created_at
+        SELECT m.id, m.community_id, m.user_id, u.username, m.message, m.created_at
         FROM messages m
         JOIN users u ON m.user_id = u.id
-        WHERE m.community_id = ?
+        WHERE m.community_id = ? AND m.hidden_at IS NULL
         ORDER BY m.created_at DESC
     `
     if err := db.Raw(query, communityID).Scan(&messages).Error; err != nil {
@@ -199,3 +299,32 @@ func GetCommunityMessages(c *fiber.Ctx) error {
 
     return helpers.HandleSuccess(c, fiber.StatusOK, "Messages fetched successfully", messages)
 }
+
+// isMember reports whether the user belongs to the community.
+func isMember(db *gorm.DB, communityID int, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM community_members WHERE community_id = ? AND user_id = ?)", communityID, userID).
+		Scan(&exists).Error
+	return exists, err
+}
+
+// isInvited reports whether the user has a pending request to join the
+// community or code is one of its invites that can still be accepted.
+func isInvited(db *gorm.DB, communityID int, userID uuid.UUID, code string) (bool, error) {
+	var invited bool
+	err := db.Raw(`SELECT
+		EXISTS (SELECT 1 FROM community_join_requests WHERE community_id = ? AND user_id = ? AND status = ?)
+		OR EXISTS (SELECT 1 FROM community_invites WHERE community_id = ? AND code = ? AND NOT revoked
+			AND (expires_at IS NULL OR expires_at > ?) AND (max_uses IS NULL OR uses < max_uses))`,
+		communityID, userID, models.JoinRequestPending, communityID, code, time.Now()).
+		Scan(&invited).Error
+	return invited, err
+}
+
+func isValidVisibility(visibility string) bool {
+	switch visibility {
+	case models.CommunityVisibilityPublic, models.CommunityVisibilityRequest, models.CommunityVisibilityInvite:
+		return true
+	}
+	return false
+}
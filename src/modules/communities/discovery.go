@@ -0,0 +1,386 @@
+package communities
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxCommunityTags = 10
+	maxIconSize      = 2 * 1024 * 1024
+)
+
+// CommunityListing is a community with the aggregates used for discovery.
+type CommunityListing struct {
+	models.Community
+	MemberCount    int        `json:"member_count"`
+	RecentMessages int        `json:"recent_messages"`
+	LastActivityAt *time.Time `json:"last_activity_at"`
+	IsMember       bool       `json:"is_member"`
+	Score          float64    `json:"score,omitempty"`
+}
+
+// communityStatsJoins adds member counts and last-week message activity to a query over communities c.
+const communityStatsJoins = `
+	LEFT JOIN (
+		SELECT community_id, COUNT(*) AS member_count
+		FROM community_members GROUP BY community_id
+	) mc ON mc.community_id = c.id
+	LEFT JOIN (
+		SELECT community_id, MAX(created_at) AS last_activity_at,
+		       COUNT(*) FILTER (WHERE created_at > NOW() - INTERVAL '7 days') AS recent_messages
+		FROM messages GROUP BY community_id
+	) ma ON ma.community_id = c.id
+`
+
+// GetAllCommunities lists the communities visible to the caller with optional
+// search, category and tag filters, sorting and pagination.
+func GetAllCommunities(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	limit, offset := helpers.ParsePagination(c, 20, 100)
+
+	// Invite-only communities are only listed for their members
+	where := []string{"(c.visibility <> ? OR EXISTS (SELECT 1 FROM community_members m WHERE m.community_id = c.id AND m.user_id = ?))"}
+	args := []interface{}{models.CommunityVisibilityInvite, userID}
+
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		pattern := "%" + strings.ToLower(search) + "%"
+		where = append(where, `(LOWER(c.name) LIKE ? OR LOWER(c.description) LIKE ? OR EXISTS (
+			SELECT 1 FROM community_tags ct JOIN tags t ON t.id = ct.tag_id
+			WHERE ct.community_id = c.id AND LOWER(t.tag) LIKE ?))`)
+		args = append(args, pattern, pattern, pattern)
+	}
+	if category := strings.TrimSpace(c.Query("category")); category != "" {
+		where = append(where, "LOWER(c.category) = ?")
+		args = append(args, strings.ToLower(category))
+	}
+	if tag := strings.TrimSpace(c.Query("tag")); tag != "" {
+		where = append(where, `EXISTS (SELECT 1 FROM community_tags ct JOIN tags t ON t.id = ct.tag_id
+			WHERE ct.community_id = c.id AND t.tag = ?)`)
+		args = append(args, normalizeTag(tag))
+	}
+
+	var orderBy string
+	switch c.Query("sort", "members") {
+	case "members":
+		orderBy = "member_count DESC, c.id DESC"
+	case "activity":
+		orderBy = "recent_messages DESC, last_activity_at DESC NULLS LAST, c.id DESC"
+	case "newest":
+		orderBy = "c.created_at DESC, c.id DESC"
+	case "name":
+		orderBy = "LOWER(c.name) ASC, c.id ASC"
+	default:
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Sort must be one of members, activity, newest or name", nil)
+	}
+
+	whereClause := strings.Join(where, " AND ")
+
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM communities c WHERE "+whereClause, args...).Scan(&total).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to count communities", err)
+	}
+
+	query := `
+		SELECT c.*, COALESCE(mc.member_count, 0) AS member_count,
+		       COALESCE(ma.recent_messages, 0) AS recent_messages, ma.last_activity_at,
+		       EXISTS (SELECT 1 FROM community_members m WHERE m.community_id = c.id AND m.user_id = ?) AS is_member
+		FROM communities c
+	` + communityStatsJoins + " WHERE " + whereClause + " ORDER BY " + orderBy + " LIMIT ? OFFSET ?"
+	queryArgs := append([]interface{}{userID}, args...)
+	queryArgs = append(queryArgs, limit, offset)
+
+	var communities []CommunityListing
+	if err := db.Raw(query, queryArgs...).Scan(&communities).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch communities", err)
+	}
+	if err := attachCommunityTags(db, communities); err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community tags", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Communities fetched successfully", fiber.Map{
+		"communities": communities,
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+	})
+}
+
+// GetRecommendedCommunities ranks communities the caller has not joined by how
+// well their tags and category match the caller's interests and skills, how
+// many members share the caller's college, and overall size.
+func GetRecommendedCommunities(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	limit, _ := helpers.ParsePagination(c, 10, 50)
+
+	query := `
+		WITH profile_terms AS (
+			SELECT LOWER(i.interest_name) AS term
+			FROM user_interests ui JOIN interests i ON i.interest_id = ui.interest_id
+			WHERE ui.user_id = @user
+			UNION
+			SELECT LOWER(s.skill_name)
+			FROM user_skills us JOIN skills s ON s.skill_id = us.skill_id
+			WHERE us.user_id = @user
+		),
+		tag_matches AS (
+			SELECT ct.community_id, COUNT(*) AS matches
+			FROM community_tags ct
+			JOIN tags t ON t.id = ct.tag_id
+			JOIN profile_terms p ON p.term = LOWER(t.tag)
+			GROUP BY ct.community_id
+		),
+		college_peers AS (
+			SELECT cm.community_id, COUNT(*) AS peers
+			FROM community_members cm
+			JOIN users u ON u.id = cm.user_id
+			JOIN users me ON me.id = @user
+			WHERE u.college_name_id = me.college_name_id
+			  AND me.college_name_id <> '00000000-0000-0000-0000-000000000000'
+			  AND u.id <> me.id
+			GROUP BY cm.community_id
+		)
+		SELECT c.*, COALESCE(mc.member_count, 0) AS member_count,
+		       COALESCE(ma.recent_messages, 0) AS recent_messages, ma.last_activity_at,
+		       FALSE AS is_member,
+		       3 * COALESCE(tm.matches, 0)
+		       + 2 * (CASE WHEN EXISTS (SELECT 1 FROM profile_terms p WHERE p.term = LOWER(c.category)) THEN 1 ELSE 0 END)
+		       + 1.5 * LN(1 + COALESCE(cp.peers, 0))
+		       + 0.5 * LN(1 + COALESCE(mc.member_count, 0)) AS score
+		FROM communities c
+	` + communityStatsJoins + `
+		LEFT JOIN tag_matches tm ON tm.community_id = c.id
+		LEFT JOIN college_peers cp ON cp.community_id = c.id
+		WHERE c.visibility <> @invite
+		  AND NOT EXISTS (SELECT 1 FROM community_members m WHERE m.community_id = c.id AND m.user_id = @user)
+		  AND NOT EXISTS (SELECT 1 FROM community_bans b WHERE b.community_id = c.id AND b.user_id = @user)
+		ORDER BY score DESC, member_count DESC, c.id DESC
+		LIMIT @limit
+	`
+
+	var communities []CommunityListing
+	if err := db.Raw(query, map[string]interface{}{
+		"user":   userID,
+		"invite": models.CommunityVisibilityInvite,
+		"limit":  limit,
+	}).Scan(&communities).Error; err != nil {
+		log.Printf("Error fetching recommended communities: %v\n", err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch recommended communities", err)
+	}
+	if err := attachCommunityTags(db, communities); err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community tags", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Recommended communities fetched successfully", communities)
+}
+
+// UploadCommunityIcon replaces the community's icon with an uploaded image.
+func UploadCommunityIcon(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	file, err := c.FormFile("icon")
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Icon upload failed", err)
+	}
+	if file.Size > maxIconSize {
+		return helpers.HandleError(c, fiber.StatusRequestEntityTooLarge, "Icon must be at most 2MB", nil)
+	}
+
+	fileContent, err := file.Open()
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to open file", err)
+	}
+	defer fileContent.Close()
+
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(fileContent, header)
+	if !strings.HasPrefix(http.DetectContentType(header[:n]), "image/") {
+		return helpers.HandleError(c, fiber.StatusUnsupportedMediaType, "Icon must be an image", nil)
+	}
+
+	fileName := fmt.Sprintf("%d-%s-%s", communityID, uuid.New().String(), file.Filename)
+	publicURL, err := uploadToSupabase(fileName, io.MultiReader(bytes.NewReader(header[:n]), fileContent))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to upload icon to storage", err)
+	}
+
+	if err := db.Model(&models.Community{}).Where("id = ?", communityID).Update("icon_url", publicURL).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to update community icon", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Community icon updated successfully", fiber.Map{"icon_url": publicURL})
+}
+
+// setCommunityTags replaces the community's tags, adding new entries to the shared tags vocabulary.
+func setCommunityTags(tx *gorm.DB, communityID int, tags []string) error {
+	if err := tx.Where("community_id = ?", communityID).Delete(&models.CommunityTag{}).Error; err != nil {
+		return err
+	}
+
+	normalized := normalizeTags(tags)
+	if len(normalized) == 0 {
+		return nil
+	}
+
+	for _, tag := range normalized {
+		if err := tx.Exec("INSERT INTO tags (tag) VALUES (?) ON CONFLICT (tag) DO NOTHING", tag).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Exec(`INSERT INTO community_tags (community_id, tag_id)
+		SELECT ?, id FROM tags WHERE tag IN ? ON CONFLICT DO NOTHING`, communityID, normalized).Error
+}
+
+// attachCommunityTags loads the tags of every listed community in a single query.
+func attachCommunityTags(db *gorm.DB, communities []CommunityListing) error {
+	if len(communities) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(communities))
+	for i, community := range communities {
+		ids[i] = community.ID
+	}
+	tagsByCommunity, err := fetchCommunityTags(db, ids)
+	if err != nil {
+		return err
+	}
+	for i := range communities {
+		communities[i].Tags = tagsByCommunity[communities[i].ID]
+		if communities[i].Tags == nil {
+			communities[i].Tags = []string{}
+		}
+	}
+	return nil
+}
+
+func fetchCommunityTags(db *gorm.DB, communityIDs []int) (map[int][]string, error) {
+	var rows []struct {
+		CommunityID int
+		Tag         string
+	}
+	if err := db.Table("community_tags").
+		Select("community_tags.community_id, tags.tag").
+		Joins("JOIN tags ON tags.id = community_tags.tag_id").
+		Where("community_tags.community_id IN ?", communityIDs).
+		Order("tags.tag").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	tagsByCommunity := make(map[int][]string)
+	for _, row := range rows {
+		tagsByCommunity[row.CommunityID] = append(tagsByCommunity[row.CommunityID], row.Tag)
+	}
+	return tagsByCommunity, nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// normalizeTags lowercases, deduplicates and caps the number of tags.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+		if len(normalized) == maxCommunityTags {
+			break
+		}
+	}
+	return normalized
+}
+
+func uploadToSupabase(fileName string, fileContent io.Reader) (string, error) {
+	bucketName := "file-buckets"
+	folderName := "communities"
+	apiURL := os.Getenv("STORAGE_URL")
+	authToken := "Bearer " + os.Getenv("SERVICE_ROLE_SECRET")
+
+	if apiURL == "" {
+		return "", fmt.Errorf("STORAGE_URL is not set in the environment variables")
+	}
+
+	// Add timestamp to filename
+	timestamp := time.Now().Unix()
+	uniqueFileName := fmt.Sprintf("%d_%s", timestamp, fileName)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", uniqueFileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to create multipart file: %w", err)
+	}
+	_, err = io.Copy(part, fileContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy file content: %w", err)
+	}
+	writer.Close()
+
+	objectPath := fmt.Sprintf("%s/%s", folderName, uniqueFileName)
+	requestURL := fmt.Sprintf("%s/object/%s/%s", apiURL, bucketName, objectPath)
+
+	req, err := http.NewRequest("POST", requestURL, body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", authToken)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		log.Printf("Upload failed. Response Body: %s\n", string(respBody))
+		return "", fmt.Errorf("upload failed with status: %s", resp.Status)
+	}
+
+	publicURL := fmt.Sprintf("%s/object/public/%s/%s", apiURL, bucketName, objectPath)
+	return publicURL, nil
+}
@@ -0,0 +1,435 @@
+package communities
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxInviteLifetime = 30 * 24 * time.Hour
+
+var errInviteExhausted = errors.New("invite has reached its usage limit")
+
+// UpdateCommunitySettings lets admins change a community's name, description,
+// visibility, category and tags.
+func UpdateCommunitySettings(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	var input struct {
+		Name        *string   `json:"name"`
+		Description *string   `json:"description"`
+		Visibility  *string   `json:"visibility"`
+		Category    *string   `json:"category"`
+		Tags        *[]string `json:"tags"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid input data", err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != nil {
+		if *input.Name == "" {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Community name cannot be empty", nil)
+		}
+		updates["name"] = *input.Name
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+	if input.Visibility != nil {
+		if !isValidVisibility(*input.Visibility) {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Visibility must be one of public, request or invite", nil)
+		}
+		updates["visibility"] = *input.Visibility
+	}
+	if input.Category != nil {
+		updates["category"] = strings.TrimSpace(*input.Category)
+	}
+	if len(updates) == 0 && input.Tags == nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "No settings to update", nil)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Community{}).Where("id = ?", communityID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if input.Tags != nil {
+			return setCommunityTags(tx, communityID, *input.Tags)
+		}
+		return nil
+	})
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to update community", err)
+	}
+
+	var community models.Community
+	if err := db.First(&community, communityID).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch updated community", err)
+	}
+	tagsByCommunity, err := fetchCommunityTags(db, []int{communityID})
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community tags", err)
+	}
+	community.Tags = tagsByCommunity[communityID]
+	if community.Tags == nil {
+		community.Tags = []string{}
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Community updated successfully", community)
+}
+
+// CreateInvite generates an invite link, optionally limited in lifetime and number of uses.
+func CreateInvite(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	var input struct {
+		ExpiresInHours int `json:"expires_in_hours" validate:"gte=0"`
+		MaxUses        int `json:"max_uses" validate:"gte=0"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid input data", err)
+	}
+	if err := helpers.Validate(input); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "expires_in_hours and max_uses cannot be negative", err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to generate invite code", err)
+	}
+
+	invite := models.CommunityInvite{
+		CommunityID: communityID,
+		Code:        code,
+		CreatedBy:   actorID,
+		CreatedAt:   time.Now(),
+	}
+	if input.ExpiresInHours > 0 {
+		lifetime := time.Duration(input.ExpiresInHours) * time.Hour
+		if lifetime > maxInviteLifetime {
+			lifetime = maxInviteLifetime
+		}
+		expiresAt := time.Now().Add(lifetime)
+		invite.ExpiresAt = &expiresAt
+	}
+	if input.MaxUses > 0 {
+		invite.MaxUses = &input.MaxUses
+	}
+
+	if err := db.Create(&invite).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create invite", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Invite created successfully", fiber.Map{
+		"invite":      invite,
+		"invite_link": fmt.Sprintf("%s/api/v1/communities/invites/%s/accept", c.BaseURL(), invite.Code),
+	})
+}
+
+func GetInvites(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	var invites []models.CommunityInvite
+	if err := db.Where("community_id = ?", communityID).Order("created_at DESC").Find(&invites).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch invites", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Invites fetched successfully", invites)
+}
+
+func RevokeInvite(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+	inviteID, err := strconv.Atoi(c.Params("invite_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid invite ID format", err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	result := db.Model(&models.CommunityInvite{}).
+		Where("id = ? AND community_id = ?", inviteID, communityID).
+		Update("revoked", true)
+	if result.Error != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to revoke invite", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Invite not found", nil)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Invite revoked successfully", nil)
+}
+
+// AcceptInvite adds the caller to the invite's community, regardless of its visibility.
+func AcceptInvite(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	var invite models.CommunityInvite
+	if err := db.Where("code = ?", c.Params("code")).First(&invite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return helpers.HandleError(c, fiber.StatusNotFound, "Invite not found", nil)
+		}
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch invite", err)
+	}
+
+	if invite.Revoked || (invite.ExpiresAt != nil && invite.ExpiresAt.Before(time.Now())) {
+		return helpers.HandleError(c, fiber.StatusGone, "This invite is no longer valid", nil)
+	}
+
+	member, err := isMember(db, invite.CommunityID, userID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to verify membership", err)
+	}
+	if member {
+		return helpers.HandleError(c, fiber.StatusConflict, "User is already a member", nil)
+	}
+
+	banned, err := isBanned(db, invite.CommunityID, userID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to check ban status", err)
+	}
+	if banned {
+		return helpers.HandleError(c, fiber.StatusForbidden, "You are banned from this community", nil)
+	}
+
+	communityMember := models.CommunityMember{
+		UserID:      userID,
+		CommunityID: invite.CommunityID,
+		Role:        models.CommunityRoleMember,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Claim a use atomically so concurrent accepts cannot exceed max_uses
+		result := tx.Model(&models.CommunityInvite{}).
+			Where("id = ? AND (max_uses IS NULL OR uses < max_uses)", invite.ID).
+			Update("uses", gorm.Expr("uses + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInviteExhausted
+		}
+		if err := tx.Create(&communityMember).Error; err != nil {
+			return err
+		}
+		// An invite supersedes any pending request to join
+		return tx.Model(&models.CommunityJoinRequest{}).
+			Where("community_id = ? AND user_id = ? AND status = ?", invite.CommunityID, userID, models.JoinRequestPending).
+			Update("status", models.JoinRequestApproved).Error
+	})
+	if errors.Is(err, errInviteExhausted) {
+		return helpers.HandleError(c, fiber.StatusGone, "This invite has reached its usage limit", err)
+	}
+	if err != nil {
+		log.Printf("Error accepting invite %d: %v\n", invite.ID, err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to join community", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Successfully joined the community", communityMember)
+}
+
+// createJoinRequest files a pending request, reopening a previously reviewed one if present.
+func createJoinRequest(db *gorm.DB, communityID int, userID uuid.UUID, message string) (*models.CommunityJoinRequest, error) {
+	var joinRequest models.CommunityJoinRequest
+	err := db.Where("community_id = ? AND user_id = ?", communityID, userID).First(&joinRequest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		joinRequest = models.CommunityJoinRequest{
+			CommunityID: communityID,
+			UserID:      userID,
+			Message:     message,
+			Status:      models.JoinRequestPending,
+			CreatedAt:   time.Now(),
+		}
+		if err := db.Create(&joinRequest).Error; err != nil {
+			return nil, err
+		}
+		return &joinRequest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if joinRequest.Status == models.JoinRequestPending {
+		return &joinRequest, nil
+	}
+
+	updates := map[string]interface{}{
+		"status":      models.JoinRequestPending,
+		"message":     message,
+		"reviewed_by": nil,
+		"reviewed_at": nil,
+		"created_at":  time.Now(),
+	}
+	if err := db.Model(&joinRequest).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return &joinRequest, nil
+}
+
+func GetJoinRequests(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	status := c.Query("status", models.JoinRequestPending)
+	limit, offset := helpers.ParsePagination(c, 50, 100)
+
+	type JoinRequestWithUser struct {
+		models.CommunityJoinRequest
+		Username      string `json:"username"`
+		ProfilePicURL string `json:"profile_pic_url"`
+	}
+
+	var joinRequests []JoinRequestWithUser
+	if err := db.Table("community_join_requests").
+		Select("community_join_requests.*, users.username, users.profile_pic_url").
+		Joins("JOIN users ON users.id = community_join_requests.user_id").
+		Where("community_join_requests.community_id = ? AND community_join_requests.status = ?", communityID, status).
+		Order("community_join_requests.created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&joinRequests).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch join requests", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Join requests fetched successfully", joinRequests)
+}
+
+func ApproveJoinRequest(c *fiber.Ctx) error {
+	return reviewJoinRequest(c, true)
+}
+
+func DenyJoinRequest(c *fiber.Ctx) error {
+	return reviewJoinRequest(c, false)
+}
+
+func reviewJoinRequest(c *fiber.Ctx, approve bool) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+	requestID, err := strconv.Atoi(c.Params("request_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid join request ID format", err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	var joinRequest models.CommunityJoinRequest
+	if err := db.Where("id = ? AND community_id = ?", requestID, communityID).First(&joinRequest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return helpers.HandleError(c, fiber.StatusNotFound, "Join request not found", nil)
+		}
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch join request", err)
+	}
+	if joinRequest.Status != models.JoinRequestPending {
+		return helpers.HandleError(c, fiber.StatusConflict, "Join request has already been reviewed", nil)
+	}
+
+	status := models.JoinRequestDenied
+	if approve {
+		banned, err := isBanned(db, communityID, joinRequest.UserID)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to check ban status", err)
+		}
+		if banned {
+			return helpers.HandleError(c, fiber.StatusConflict, "User is banned from this community", nil)
+		}
+		status = models.JoinRequestApproved
+	}
+
+	reviewedAt := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&joinRequest).Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": actorID,
+			"reviewed_at": reviewedAt,
+		}).Error; err != nil {
+			return err
+		}
+		if !approve {
+			return nil
+		}
+		return tx.Exec(`INSERT INTO community_members (user_id, community_id, role) VALUES (?, ?, ?)
+			ON CONFLICT (user_id, community_id) DO NOTHING`,
+			joinRequest.UserID, communityID, models.CommunityRoleMember).Error
+	})
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to review join request", err)
+	}
+
+	joinRequest.Status = status
+	joinRequest.ReviewedBy = &actorID
+	joinRequest.ReviewedAt = &reviewedAt
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Join request reviewed successfully", joinRequest)
+}
+
+func generateInviteCode() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,62 @@
+package communities
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/feed"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetCommunityPosts returns the posts published into a community, newest first.
+// Posts in non-public communities are only visible to members.
+func GetCommunityPosts(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	communityID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid community ID format", err)
+	}
+
+	var community models.Community
+	if err := db.First(&community, communityID).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Community not found", err)
+	}
+
+	if community.Visibility != models.CommunityVisibilityPublic {
+		member, err := isMember(db, communityID, userID)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to verify membership", err)
+		}
+		if !member {
+			return helpers.HandleError(c, fiber.StatusForbidden, "You are not a member of this community", nil)
+		}
+	}
+
+	limit, offset := helpers.ParsePagination(c, 10, 50)
+
+	query := feed.PostQuery(db, userID).Where("posts.community_id = ?", communityID)
+
+	if postType := c.Query("type"); postType != "" {
+		query = query.Where("posts.post_type = ?", postType)
+	}
+
+	var posts []models.Post
+	if err := query.Order("posts.created_at DESC").Limit(limit).Offset(offset).Find(&posts).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community posts", err)
+	}
+
+	feedPosts := feed.BuildFeedPosts(posts)
+	if err := feed.ApplyViewerState(userID, feedPosts); err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community posts", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Community posts fetched successfully", feedPosts)
+}
@@ -0,0 +1,480 @@
+package communities
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxMuteDuration = 30 * 24 * time.Hour
+
+var (
+	ErrNotMember        = errors.New("user is not a member of the community")
+	ErrInsufficientRole = errors.New("insufficient community role")
+	ErrMuted            = errors.New("user is muted in the community")
+)
+
+var roleRanks = map[string]int{
+	models.CommunityRoleMember:    1,
+	models.CommunityRoleModerator: 2,
+	models.CommunityRoleAdmin:     3,
+	models.CommunityRoleOwner:     4,
+}
+
+// RoleRank returns the privilege level of a role; unknown roles rank lowest.
+func RoleRank(role string) int {
+	return roleRanks[role]
+}
+
+// GetMembership returns the user's membership in the community or ErrNotMember.
+func GetMembership(db *gorm.DB, communityID int, userID uuid.UUID) (*models.CommunityMember, error) {
+	var member models.CommunityMember
+	err := db.Where("community_id = ? AND user_id = ?", communityID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotMember
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// CanSendMessage checks that the user is a member of the community and not currently muted.
+func CanSendMessage(db *gorm.DB, communityID int, userID uuid.UUID) (*models.CommunityMember, error) {
+	member, err := GetMembership(db, communityID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.MutedUntil != nil && member.MutedUntil.After(time.Now()) {
+		return member, ErrMuted
+	}
+	return member, nil
+}
+
+// requireRole returns the caller's membership if they hold at least minRole.
+func requireRole(db *gorm.DB, communityID int, userID uuid.UUID, minRole string) (*models.CommunityMember, error) {
+	member, err := GetMembership(db, communityID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if RoleRank(member.Role) < RoleRank(minRole) {
+		return nil, ErrInsufficientRole
+	}
+	return member, nil
+}
+
+// handleMembershipError maps membership and role errors onto HTTP responses,
+// leaving the rest to helpers.HandleFiberError.
+func handleMembershipError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ErrNotMember):
+		return helpers.HandleError(c, fiber.StatusForbidden, "You are not a member of this community", err)
+	case errors.Is(err, ErrInsufficientRole):
+		return helpers.HandleError(c, fiber.StatusForbidden, "You do not have permission to perform this action", err)
+	}
+	return helpers.HandleFiberError(c, err, "Failed to verify community role")
+}
+
+func isBanned(db *gorm.DB, communityID int, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM community_bans WHERE community_id = ? AND user_id = ?)", communityID, userID).
+		Scan(&exists).Error
+	return exists, err
+}
+
+// parseModerationParams reads the caller, the community ID and, when targetParam
+// is set, the target user ID from the request.
+func parseModerationParams(c *fiber.Ctx, targetParam string) (uuid.UUID, int, uuid.UUID, error) {
+	actorID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return uuid.Nil, 0, uuid.Nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or missing user_id")
+	}
+	communityID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, 0, uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid community ID format")
+	}
+	if targetParam == "" {
+		return actorID, communityID, uuid.Nil, nil
+	}
+	targetID, err := uuid.Parse(c.Params(targetParam))
+	if err != nil {
+		return uuid.Nil, 0, uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format")
+	}
+	return actorID, communityID, targetID, nil
+}
+
+func GetCommunityMembers(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+	if _, err := GetMembership(db, communityID, actorID); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	limit, offset := helpers.ParsePagination(c, 50, 100)
+
+	type MemberWithUser struct {
+		UserID        uuid.UUID  `json:"user_id"`
+		Username      string     `json:"username"`
+		ProfilePicURL string     `json:"profile_pic_url"`
+		Role          string     `json:"role"`
+		MutedUntil    *time.Time `json:"muted_until,omitempty"`
+		JoinedAt      time.Time  `json:"joined_at"`
+	}
+
+	var members []MemberWithUser
+	query := `
+		SELECT cm.user_id, u.username, u.profile_pic_url, cm.role, cm.muted_until, cm.joined_at
+		FROM community_members cm
+		JOIN users u ON u.id = cm.user_id
+		WHERE cm.community_id = ?
+		ORDER BY CASE cm.role WHEN 'owner' THEN 1 WHEN 'admin' THEN 2 WHEN 'moderator' THEN 3 ELSE 4 END, cm.joined_at
+		LIMIT ? OFFSET ?
+	`
+	if err := db.Raw(query, communityID, limit, offset).Scan(&members).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch community members", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Community members fetched successfully", members)
+}
+
+// UpdateMemberRole promotes or demotes a member. Owners may hand over ownership
+// by assigning the owner role, which demotes them to admin.
+func UpdateMemberRole(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, targetID, err := parseModerationParams(c, "user_id")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	var input struct {
+		Role string `json:"role" validate:"required,oneof=owner admin moderator member"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid input data", err)
+	}
+	if err := helpers.Validate(input); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Role must be one of owner, admin, moderator or member", err)
+	}
+
+	actor, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin)
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+	if actorID == targetID {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "You cannot change your own role", nil)
+	}
+
+	target, err := GetMembership(db, communityID, targetID)
+	if errors.Is(err, ErrNotMember) {
+		return helpers.HandleError(c, fiber.StatusNotFound, "User is not a member of this community", err)
+	}
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch member", err)
+	}
+
+	if input.Role == models.CommunityRoleOwner {
+		if actor.Role != models.CommunityRoleOwner {
+			return helpers.HandleError(c, fiber.StatusForbidden, "Only the owner can transfer ownership", nil)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.CommunityMember{}).Where("id = ?", actor.ID).
+				Update("role", models.CommunityRoleAdmin).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.CommunityMember{}).Where("id = ?", target.ID).
+				Update("role", models.CommunityRoleOwner).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.Community{}).Where("id = ?", communityID).Update("created_by", targetID).Error
+		})
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to transfer ownership", err)
+		}
+		target.Role = models.CommunityRoleOwner
+		return helpers.HandleSuccess(c, fiber.StatusOK, "Ownership transferred successfully", target)
+	}
+
+	// Actors can only manage members below them and grant roles below their own
+	if RoleRank(actor.Role) <= RoleRank(target.Role) || RoleRank(actor.Role) <= RoleRank(input.Role) {
+		return helpers.HandleError(c, fiber.StatusForbidden, "You cannot assign this role to this member", nil)
+	}
+
+	if err := db.Model(target).Update("role", input.Role).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to update member role", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Member role updated successfully", target)
+}
+
+func KickMember(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, targetID, err := parseModerationParams(c, "user_id")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	actor, err := requireRole(db, communityID, actorID, models.CommunityRoleModerator)
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	target, err := GetMembership(db, communityID, targetID)
+	if errors.Is(err, ErrNotMember) {
+		return helpers.HandleError(c, fiber.StatusNotFound, "User is not a member of this community", err)
+	}
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch member", err)
+	}
+	if RoleRank(actor.Role) <= RoleRank(target.Role) {
+		return helpers.HandleError(c, fiber.StatusForbidden, "You cannot remove this member", nil)
+	}
+
+	if err := db.Delete(target).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to remove member", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Member removed successfully", nil)
+}
+
+// BanMember removes the user named by :user_id from the community, if they are
+// a member, and prevents them from rejoining.
+func BanMember(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, targetID, err := parseModerationParams(c, "user_id")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	// The body is optional and only carries the reason
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid input data", err)
+		}
+	}
+
+	actor, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin)
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+	if actorID == targetID {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "You cannot ban yourself", nil)
+	}
+
+	target, err := GetMembership(db, communityID, targetID)
+	if err != nil && !errors.Is(err, ErrNotMember) {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch member", err)
+	}
+	if target != nil && RoleRank(actor.Role) <= RoleRank(target.Role) {
+		return helpers.HandleError(c, fiber.StatusForbidden, "You cannot ban this member", nil)
+	}
+
+	banned, err := isBanned(db, communityID, targetID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to check ban status", err)
+	}
+	if banned {
+		return helpers.HandleError(c, fiber.StatusConflict, "User is already banned", nil)
+	}
+
+	ban := models.CommunityBan{
+		CommunityID: communityID,
+		UserID:      targetID,
+		BannedBy:    actorID,
+		Reason:      input.Reason,
+		CreatedAt:   time.Now(),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if target != nil {
+			if err := tx.Delete(target).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&ban).Error
+	})
+	if err != nil {
+		log.Printf("Error banning user %s from community %d: %v\n", targetID, communityID, err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to ban user", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "User banned successfully", ban)
+}
+
+func UnbanMember(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, targetID, err := parseModerationParams(c, "user_id")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleAdmin); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	result := db.Where("community_id = ? AND user_id = ?", communityID, targetID).Delete(&models.CommunityBan{})
+	if result.Error != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to unban user", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return helpers.HandleError(c, fiber.StatusNotFound, "User is not banned", nil)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "User unbanned successfully", nil)
+}
+
+func GetCommunityBans(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleModerator); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	var bans []models.CommunityBan
+	if err := db.Where("community_id = ?", communityID).Order("created_at DESC").Find(&bans).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch bans", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Bans fetched successfully", bans)
+}
+
+// MuteMember stops a member from sending messages for the given number of minutes.
+func MuteMember(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, targetID, err := parseModerationParams(c, "user_id")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	var input struct {
+		DurationMinutes int `json:"duration_minutes" validate:"required,gt=0"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid input data", err)
+	}
+	if err := helpers.Validate(input); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "duration_minutes must be greater than zero", err)
+	}
+	duration := time.Duration(input.DurationMinutes) * time.Minute
+	if duration > maxMuteDuration {
+		duration = maxMuteDuration
+	}
+
+	actor, err := requireRole(db, communityID, actorID, models.CommunityRoleModerator)
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	target, err := GetMembership(db, communityID, targetID)
+	if errors.Is(err, ErrNotMember) {
+		return helpers.HandleError(c, fiber.StatusNotFound, "User is not a member of this community", err)
+	}
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch member", err)
+	}
+	if RoleRank(actor.Role) <= RoleRank(target.Role) {
+		return helpers.HandleError(c, fiber.StatusForbidden, "You cannot mute this member", nil)
+	}
+
+	mutedUntil := time.Now().Add(duration)
+	if err := db.Model(target).Update("muted_until", mutedUntil).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to mute member", err)
+	}
+	target.MutedUntil = &mutedUntil
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Member muted successfully", target)
+}
+
+func UnmuteMember(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, targetID, err := parseModerationParams(c, "user_id")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	if _, err := requireRole(db, communityID, actorID, models.CommunityRoleModerator); err != nil {
+		return handleMembershipError(c, err)
+	}
+
+	target, err := GetMembership(db, communityID, targetID)
+	if errors.Is(err, ErrNotMember) {
+		return helpers.HandleError(c, fiber.StatusNotFound, "User is not a member of this community", err)
+	}
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch member", err)
+	}
+
+	if err := db.Model(target).Update("muted_until", nil).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to unmute member", err)
+	}
+	target.MutedUntil = nil
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Member unmuted successfully", target)
+}
+
+// DeleteCommunityMessage lets authors delete their own messages and moderators
+// delete messages from members ranked below them.
+func DeleteCommunityMessage(c *fiber.Ctx) error {
+	db := database.DB
+
+	actorID, communityID, _, err := parseModerationParams(c, "")
+	if err != nil {
+		return handleMembershipError(c, err)
+	}
+	messageID, err := strconv.Atoi(c.Params("message_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid message ID format", err)
+	}
+
+	var message models.Message
+	if err := db.Where("id = ? AND community_id = ?", messageID, communityID).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return helpers.HandleError(c, fiber.StatusNotFound, "Message not found", nil)
+		}
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch message", err)
+	}
+
+	if message.UserID != actorID {
+		actor, err := requireRole(db, communityID, actorID, models.CommunityRoleModerator)
+		if err != nil {
+			return handleMembershipError(c, err)
+		}
+		author, err := GetMembership(db, communityID, message.UserID)
+		if err != nil && !errors.Is(err, ErrNotMember) {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch message author", err)
+		}
+		if author != nil && RoleRank(actor.Role) <= RoleRank(author.Role) {
+			return helpers.HandleError(c, fiber.StatusForbidden, "You cannot delete this message", nil)
+		}
+	}
+
+	if err := db.Delete(&message).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to delete message", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Message deleted successfully", nil)
+}
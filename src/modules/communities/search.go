@@ -0,0 +1,182 @@
+package communities
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	searchDefaultLimit = 20
+	searchMaxLimit     = 50
+	searchContextSize  = 2
+	searchMaxContext   = 5
+)
+
+// MessageSearchResult is a single full-text search hit with its highlighted
+// snippet and the messages posted around it.
+type MessageSearchResult struct {
+	ID            int              `json:"id"`
+	CommunityID   int              `json:"community_id"`
+	CommunityName string           `json:"community_name"`
+	UserID        uuid.UUID        `json:"user_id"`
+	Username      string           `json:"username"`
+	ProfilePicURL string           `json:"profile_pic_url"`
+	Message       string           `json:"message"`
+	Snippet       string           `json:"snippet"`
+	Rank          float64          `json:"rank"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Before        []MessageContext `json:"before" gorm:"-"`
+	After         []MessageContext `json:"after" gorm:"-"`
+}
+
+// MessageContext is a neighbouring message returned alongside a search hit.
+type MessageContext struct {
+	ID        int       `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SearchCommunityMessages searches the messages of a single community the caller belongs to.
+func SearchCommunityMessages(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	communityID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid community ID format", err)
+	}
+
+	member, err := isMember(db, communityID, userID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to verify membership", err)
+	}
+	if !member {
+		return helpers.HandleError(c, fiber.StatusForbidden, "You are not a member of this community", nil)
+	}
+
+	return searchMessages(c, db, userID, &communityID)
+}
+
+// SearchMessages searches the messages of every community the caller has joined.
+func SearchMessages(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+
+	return searchMessages(c, db, userID, nil)
+}
+
+func searchMessages(c *fiber.Ctx, db *gorm.DB, userID uuid.UUID, communityID *int) error {
+	searchQuery := strings.TrimSpace(c.Query("query"))
+	if searchQuery == "" {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Search query is required", nil)
+	}
+
+	limit, offset := helpers.ParsePagination(c, searchDefaultLimit, searchMaxLimit)
+
+	contextSize, err := strconv.Atoi(c.Query("context", strconv.Itoa(searchContextSize)))
+	if err != nil || contextSize < 0 {
+		contextSize = searchContextSize
+	}
+	if contextSize > searchMaxContext {
+		contextSize = searchMaxContext
+	}
+
+	// Membership is enforced by the join on community_members, so results never
+	// include communities the caller has not joined.
+	query := `
+		SELECT m.id, m.community_id, c.name AS community_name, m.user_id, u.username, u.profile_pic_url,
+		       m.message, m.created_at,
+		       ts_headline('english', m.message, q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10') AS snippet,
+		       ts_rank(m.search_vector, q) AS rank
+		FROM messages m
+		JOIN community_members cm ON cm.community_id = m.community_id AND cm.user_id = ?
+		JOIN communities c ON c.id = m.community_id
+		JOIN users u ON u.id = m.user_id,
+		     websearch_to_tsquery('english', ?) q
+		WHERE m.search_vector @@ q AND m.hidden_at IS NULL
+	`
+	args := []interface{}{userID, searchQuery}
+	if communityID != nil {
+		query += " AND m.community_id = ?"
+		args = append(args, *communityID)
+	}
+	query += " ORDER BY rank DESC, m.created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	var results []MessageSearchResult
+	if err := db.Raw(query, args...).Scan(&results).Error; err != nil {
+		log.Printf("Error searching messages: %v\n", err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to search messages", err)
+	}
+
+	for i := range results {
+		results[i].Before, results[i].After = []MessageContext{}, []MessageContext{}
+		if contextSize == 0 {
+			continue
+		}
+		before, after, err := fetchMessageContext(db, results[i].CommunityID, results[i].ID, contextSize)
+		if err != nil {
+			log.Printf("Error fetching context for message %d: %v\n", results[i].ID, err)
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch message context", err)
+		}
+		results[i].Before, results[i].After = before, after
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Messages searched successfully", fiber.Map{
+		"query":   searchQuery,
+		"limit":   limit,
+		"offset":  offset,
+		"results": results,
+	})
+}
+
+// fetchMessageContext returns up to size messages posted immediately before and
+// after messageID in the same community, both in chronological order.
+func fetchMessageContext(db *gorm.DB, communityID, messageID, size int) ([]MessageContext, []MessageContext, error) {
+	query := `
+		SELECT * FROM (
+			(SELECT m.id, m.user_id, u.username, m.message, m.created_at
+			 FROM messages m JOIN users u ON u.id = m.user_id
+			 WHERE m.community_id = ? AND m.id < ? AND m.hidden_at IS NULL
+			 ORDER BY m.id DESC LIMIT ?)
+			UNION ALL
+			(SELECT m.id, m.user_id, u.username, m.message, m.created_at
+			 FROM messages m JOIN users u ON u.id = m.user_id
+			 WHERE m.community_id = ? AND m.id > ? AND m.hidden_at IS NULL
+			 ORDER BY m.id ASC LIMIT ?)
+		) AS surrounding
+		ORDER BY id ASC
+	`
+	var surrounding []MessageContext
+	if err := db.Raw(query, communityID, messageID, size, communityID, messageID, size).Scan(&surrounding).Error; err != nil {
+		return nil, nil, err
+	}
+
+	before, after := []MessageContext{}, []MessageContext{}
+	for _, message := range surrounding {
+		if message.ID < messageID {
+			before = append(before, message)
+		} else {
+			after = append(after, message)
+		}
+	}
+	return before, after, nil
+}
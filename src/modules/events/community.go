@@ -0,0 +1,68 @@
+package events
+
+import (
+	"Backend/src/core/models"
+	"Backend/src/modules/communities"
+	"Backend/src/modules/notifications"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// VisibleCommunityItemsClause hides events and workshops of non-public communities
+// the viewer has not joined. It takes the viewer's id.
+const VisibleCommunityItemsClause = `(community_id IS NULL
+	OR community_id IN (SELECT id FROM communities WHERE visibility = 'public')
+	OR community_id IN (SELECT community_id FROM community_members WHERE user_id = ?))`
+
+// resolveCommunity loads the community named by the optional community_id form
+// field. Only moderators and above may schedule items for a community.
+func resolveCommunity(db *gorm.DB, form *multipart.Form, userID uuid.UUID) (*models.Community, error) {
+	if len(form.Value["community_id"]) == 0 || form.Value["community_id"][0] == "" {
+		return nil, nil
+	}
+
+	communityID, err := strconv.Atoi(form.Value["community_id"][0])
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid community ID format")
+	}
+
+	member, err := communities.GetMembership(db, communityID, userID)
+	if errors.Is(err, communities.ErrNotMember) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "You are not a member of this community")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify community membership: %w", err)
+	}
+	if communities.RoleRank(member.Role) < communities.RoleRank(models.CommunityRoleModerator) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Only community moderators can create community events")
+	}
+
+	var community models.Community
+	if err := db.First(&community, communityID).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Community not found")
+	}
+	return &community, nil
+}
+
+// notifyCommunityMembers tells every member except the creator about a new community event or workshop.
+func notifyCommunityMembers(db *gorm.DB, community models.Community, creatorID uuid.UUID, kind, title string) {
+	var memberIDs []uuid.UUID
+	if err := db.Table("community_members").
+		Where("community_id = ? AND user_id <> ?", community.ID, creatorID).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		log.Printf("Error fetching members of community %d: %v\n", community.ID, err)
+		return
+	}
+
+	message := fmt.Sprintf("New %s in %s: %s", kind, community.Name, title)
+	if err := notifications.NotifyUsers(db, memberIDs, "community_event", "New community "+kind, message); err != nil {
+		log.Printf("Error notifying members of community %d: %v\n", community.ID, err)
+	}
+}
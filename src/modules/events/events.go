@@ -47,6 +47,14 @@ func CreateEvent(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusBadRequest, "Failed to parse form data", err)
 	}
 
+	community, err := resolveCommunity(db, form, userID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to verify community membership")
+	}
+	if community != nil {
+		body.CommunityID = &community.ID
+	}
+
 	body.Title = form.Value["title"][0]
 	body.Theme = form.Value["theme"][0]
 	body.Description = form.Value["description"][0]
@@ -123,6 +131,10 @@ func CreateEvent(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create event", result.Error)
 	}
 
+	if community != nil {
+		go notifyCommunityMembers(db, *community, userID, "event", body.Title)
+	}
+
 	return helpers.HandleSuccess(c, fiber.StatusCreated, "Event created successfully", body)
 }
 
@@ -153,6 +165,14 @@ func CreateWorkshop(c *fiber.Ctx) error {
 	body := new(models.Workshop)
 	body.UserID = userID
 
+	community, err := resolveCommunity(db, form, userID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to verify community membership")
+	}
+	if community != nil {
+		body.CommunityID = &community.ID
+	}
+
 	// Required fields
 	body.Title = form.Value["title"][0]
 	body.Date, _ = time.Parse(time.RFC3339, form.Value["date"][0]) // Parse date (format: YYYY-MM-DDTHH:MM:SSZ)
@@ -223,6 +243,10 @@ func CreateWorkshop(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create workshop", result.Error)
 	}
 
+	if community != nil {
+		go notifyCommunityMembers(db, *community, userID, "workshop", body.Title)
+	}
+
 	return helpers.HandleSuccess(c, fiber.StatusCreated, "Workshop created successfully", body)
 }
 
@@ -511,7 +535,8 @@ func GetEventByID(c *fiber.Ctx) error {
 	eventID := c.Params("id")
 
 	var event models.Event
-	if err := db.Table("events").Where("id = ?", eventID).First(&event).Error; err != nil {
+	if err := db.Table("events").Where("id = ?", eventID).
+		Where(VisibleCommunityItemsClause, c.Locals("user_id")).First(&event).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return helpers.HandleError(c, fiber.StatusNotFound, "Event not found", nil)
 		}
@@ -528,7 +553,8 @@ func GetWorkshopByID(c *fiber.Ctx) error {
 	workshopID := c.Params("id")
 
 	var workshop models.Workshop
-	if err := db.Table("workshops").Where("id = ?", workshopID).First(&workshop).Error; err != nil {
+	if err := db.Table("workshops").Where("id = ?", workshopID).
+		Where(VisibleCommunityItemsClause, c.Locals("user_id")).First(&workshop).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return helpers.HandleError(c, fiber.StatusNotFound, "Workshop not found", nil)
 		}
@@ -568,6 +594,7 @@ func GetEventsFeed(c *fiber.Ctx) error {
 	db := database.DB
 	var events []models.Event
 	err := db.Table("events").
+		Where(VisibleCommunityItemsClause, c.Locals("user_id")).
 		Order("date DESC").
 		Limit(15).
 		Find(&events).Error
@@ -588,6 +615,7 @@ func GetWorkshopsFeed(c *fiber.Ctx) error {
 	db := database.DB
 	var workshops []models.Workshop
 	err := db.Table("workshops").
+		Where(VisibleCommunityItemsClause, c.Locals("user_id")).
 		Order("date DESC").
 		Limit(15).
 		Find(&workshops).Error
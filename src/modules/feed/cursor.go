@@ -0,0 +1,59 @@
+package feed
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// feedCursor is the position after the last post of a feed page. Scores are
+// computed as of AsOf on every page, so the ranking does not drift with the
+// clock or with later activity while the user scrolls. Cursors expire after
+// feedCursorTTL, which is also how long the recommendation runs they rank
+// with are kept.
+type feedCursor struct {
+	AsOf  time.Time `json:"t"`
+	Score float64   `json:"s"`
+	ID    string    `json:"id"`
+}
+
+// defaultFeedCursorTTL is how long a feed can be paged before it must be
+// reloaded from the top, overridable through FEED_CURSOR_TTL_HOURS
+const defaultFeedCursorTTL = 24 * time.Hour
+
+var errInvalidCursor = errors.New("invalid feed cursor")
+
+func feedCursorTTL() time.Duration {
+	if hours := envInt("FEED_CURSOR_TTL_HOURS", 0); hours > 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return defaultFeedCursorTTL
+}
+
+// expired reports whether the cursor is too old for its ranking to be reproduced.
+func (c *feedCursor) expired(now time.Time) bool {
+	return now.Sub(c.AsOf) > feedCursorTTL()
+}
+
+// encodeCursor returns the opaque form handed to clients.
+func encodeCursor(cursor feedCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor from encodeCursor; an empty string is the first page.
+func decodeCursor(value string) (*feedCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor feedCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.AsOf.IsZero() || cursor.ID == "" {
+		return nil, errInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -1,13 +1,14 @@
 package feed
 
 import (
+	"Backend/src/core/config"
 	"Backend/src/core/database"
 	"Backend/src/core/helpers"
 	"Backend/src/core/models"
-	"errors"
+	"Backend/src/modules/analytics"
+	"Backend/src/modules/linkpreview"
 	"fmt"
 	"log"
-	"sort"
 	"strconv"
 	"time"
 
@@ -23,14 +24,88 @@ type FeedPost struct {
 	ProfilePicURL   string    `json:"profile_pic_url"`
 	Content         string    `json:"content"`
 	MediaURL        string    `json:"media_url"`
+	CommunityID     *int      `json:"community_id,omitempty"`
+	PostType        string    `json:"post_type"`
+	Visibility      string    `json:"visibility"`
+	RepostOfID      *string   `json:"repost_of_id,omitempty"`
+	OriginalPost    *OriginalPost `json:"original_post,omitempty"`
 	LikesCount      int       `json:"likes_count"`
+	ReactionCounts  map[string]int `json:"reaction_counts"`
 	CommentsCount   int       `json:"comments_count"`
+	SharesCount     int       `json:"shares_count"`
 	Tags            []string  `json:"tags"`
+	Attachments     []models.PostAttachment `json:"attachments"`
+	Poll            *PollResult `json:"poll,omitempty"`
+	LinkPreview     *models.LinkPreview `json:"link_preview,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
-	PopularityScore float64   `json:"popularity_score"`
+	EditedAt        *time.Time `json:"edited_at,omitempty"`
+	PopularityScore *float64  `json:"popularity_score,omitempty"`
+	Bookmarked      bool      `json:"bookmarked"`
+	Ranking         *RankingDebug `json:"ranking,omitempty"`
 }
 
+// OriginalPost is the post a repost or quote post points at.
+type OriginalPost struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	Username      string    `json:"username"`
+	ProfilePicURL string    `json:"profile_pic_url"`
+	Content       string    `json:"content"`
+	MediaURL      string    `json:"media_url"`
+	PostType      string    `json:"post_type"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// VisiblePostsClause hides deleted and hidden posts, plain reposts whose
+// original is gone or no longer public, posts published into non-public
+// communities the viewer has not joined, and posts whose visibility excludes
+// the viewer. Its one parameter is the viewer ID. Followers are users with a
+// connection to the author; mutual posts also need the author to follow back.
+const VisiblePostsClause = `posts.deleted_at IS NULL AND posts.hidden_at IS NULL
+	AND (posts.post_type <> 'repost' OR posts.repost_of_id IN (SELECT id FROM posts WHERE deleted_at IS NULL AND hidden_at IS NULL AND visibility = 'public'))
+	AND EXISTS (SELECT 1 FROM (SELECT CAST(? AS uuid) AS id) AS viewer WHERE
+	(posts.community_id IS NULL
+	OR posts.community_id IN (SELECT id FROM communities WHERE visibility = 'public')
+	OR posts.community_id IN (SELECT community_id FROM community_members WHERE user_id = viewer.id))
+	AND (posts.user_id = viewer.id
+	OR posts.visibility = 'public'
+	OR (posts.visibility = 'community' AND posts.community_id IN (SELECT community_id FROM community_members WHERE user_id = viewer.id))
+	OR (posts.visibility IN ('followers', 'mutual')
+	AND EXISTS (SELECT 1 FROM connections WHERE connections.user_id = viewer.id AND connections.connection_id = posts.user_id)
+	AND (posts.visibility = 'followers' OR EXISTS (SELECT 1 FROM connections WHERE connections.user_id = posts.user_id AND connections.connection_id = viewer.id)))))`
+
+// PostQuery selects live posts visible to viewerID with their author and like
+// and comment counts, ready to scan into models.Post.
+func PostQuery(db *gorm.DB, viewerID uuid.UUID) *gorm.DB {
+	return db.Table("posts").
+		Select(`posts.id, posts.user_id, posts.content, posts.media_url, posts.community_id, posts.post_type, posts.visibility, posts.repost_of_id, posts.edited_at,
+			COUNT(DISTINCT likes.user_id) AS likes_count,
+			COUNT(DISTINCT comments.id) AS comments_count,
+			posts.created_at,
+			users.username, users.profile_pic_url`).
+		Joins("JOIN users ON posts.user_id = users.id").
+		Where(VisiblePostsClause, viewerID).
+		Joins("LEFT JOIN likes ON likes.post_id = posts.id").
+		Joins("LEFT JOIN comments ON comments.post_id = posts.id AND comments.deleted_at IS NULL AND comments.hidden_at IS NULL").
+		Group("posts.id, posts.user_id, posts.content, posts.media_url, posts.community_id, posts.post_type, posts.visibility, posts.repost_of_id, posts.edited_at, posts.created_at, users.username, users.profile_pic_url")
+}
 
+// Feed page sizes
+const (
+	defaultFeedLimit = 10
+	maxFeedLimit     = 50
+)
+
+// FetchFeed returns a page of the caller's feed, best ranked first. Pages are
+// chained with the opaque ?cursor taken from the previous page's next_cursor,
+// which is null on the last page. Every page re-ranks the same window of
+// candidates, FEED_CANDIDATES_PER_SOURCE from each source as of the first
+// page, so the feed ends when that window does; truncated is true on the last
+// page when a source was cut off and older posts were left out. Cursors expire
+// after FEED_CURSOR_TTL_HOURS with 410 Gone. Each post's popularity_score is
+// its ranking score for the caller. With ?debug=true, for moderators or when
+// FEED_DEBUG is set, each post also carries the sources that proposed it and
+// each scorer's contribution to its score.
 func FetchFeed(c *fiber.Ctx) error {
 	userId, ok := c.Locals("user_id").(string)
 	if !ok || userId == "" {
@@ -41,247 +116,159 @@ func FetchFeed(c *fiber.Ctx) error {
 
 	userID, err := uuid.Parse(userId)
 	if err != nil {
-		log.Printf("Error fetching user: %v\n", err)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return helpers.HandleError(c, fiber.StatusNotFound, "User not found", nil)
-		}
-		return helpers.HandleError(c, fiber.StatusInternalServerError, "Database query failed", err)
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid user ID format", err)
 	}
-	log.Printf("Fetched user ID: %s\n", userID)
-
-	limit, offset := ParsePagination(c)
-	log.Printf("Pagination parameters - limit: %d, offset: %d\n", limit, offset)
 
-	connections, err := GetUserConnections(userID)
+	limit := c.QueryInt("limit", defaultFeedLimit)
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if limit > maxFeedLimit {
+		limit = maxFeedLimit
+	}
+	cursor, err := decodeCursor(c.Query("cursor"))
 	if err != nil {
-		log.Printf("Error fetching user connections: %v\n", err)
-		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch user connections", err)
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid cursor", err)
+	}
+	asOf := time.Now()
+	if cursor != nil {
+		if cursor.expired(asOf) {
+			return helpers.HandleError(c, fiber.StatusGone, "Feed cursor expired; reload the feed from the top", nil)
+		}
+		asOf = cursor.AsOf
+	}
+	debug := c.QueryBool("debug")
+	if debug && !debugAllowed(userID) {
+		return helpers.HandleError(c, fiber.StatusForbidden, "Ranking debug output is restricted to moderators", nil)
 	}
-	log.Printf("Fetched connections: %v\n", connections)
+	log.Printf("Feed page parameters - limit: %d, as of: %s\n", limit, asOf)
 
-	excludedPosts, err := GetLikedPostIDs(userID)
+	// One extra post tells whether there is a next page
+	ranked, capped, err := RankFeedPosts(userID, asOf, cursor, limit+1)
 	if err != nil {
-		log.Printf("Error fetching liked posts: %v\n", err)
-		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch excluded posts", err)
+		log.Printf("Error ranking feed posts: %v\n", err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch feed", err)
+	}
+	var nextCursor *string
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+		last := ranked[limit-1]
+		next := encodeCursor(feedCursor{AsOf: asOf, Score: last.Score, ID: last.ID.String()})
+		nextCursor = &next
 	}
-	log.Printf("Fetched excluded post IDs: %v\n", excludedPosts)
 
-	posts, err := GetEnhancedFeedPosts(userID, connections, excludedPosts, limit, offset)
+	feedPosts, err := loadRankedPosts(userID, ranked, debug)
 	if err != nil {
 		log.Printf("Error fetching posts: %v\n", err)
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch feed", err)
 	}
-	log.Printf("Fetched %d posts: %+v\n", len(posts), posts)
-
-	feedPosts := CalculatePopularityAndRetrieveTags(posts)
-	log.Printf("Enhanced feed posts: %+v\n", feedPosts)
+	if err := ApplyViewerState(userID, feedPosts); err != nil {
+		log.Printf("Error applying viewer state: %v\n", err)
+	}
 
-	SortByPopularity(feedPosts)
-	log.Printf("Sorted feed posts by popularity")
+	RecordImpressions(userID, feedPosts)
 
-	return helpers.HandleSuccess(c, fiber.StatusOK, "Feed fetched successfully", feedPosts)
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Feed fetched successfully", fiber.Map{
+		"posts":       feedPosts,
+		"next_cursor": nextCursor,
+		"truncated":   nextCursor == nil && capped,
+	})
 }
 
-func GetUserConnections(userID uuid.UUID) ([]string, error) {
-	db := database.DB
-	log.Printf("Fetching connections for userID: %s\n", userID)
-	var connections []struct {
-		ConnectionID string `gorm:"column:connection_id"`
-	}
-	if err := db.Table("connections").Where("user_id = ?", userID).Select("connection_id").Find(&connections).Error; err != nil {
-		return nil, err
+// loadRankedPosts loads the ranked posts for the viewer in ranking order. Their
+// popularity score is the ranking score, so it agrees with the order. debug
+// attaches the score breakdowns.
+func loadRankedPosts(viewerID uuid.UUID, ranked []RankedPost, debug bool) ([]FeedPost, error) {
+	if len(ranked) == 0 {
+		return []FeedPost{}, nil
 	}
-	connectionIDs := make([]string, len(connections))
-	for i, conn := range connections {
-		connectionIDs[i] = conn.ConnectionID
+	postIDs := make([]uuid.UUID, len(ranked))
+	for i, entry := range ranked {
+		postIDs[i] = entry.ID
 	}
-	log.Printf("Fetched connections: %v\n", connectionIDs)
-	return connectionIDs, nil
-}
 
-func GetLikedPostIDs(userID uuid.UUID) ([]string, error) {
-	db := database.DB
-	log.Printf("Fetching liked post IDs for userID: %s\n", userID)
-	var likedPosts []struct {
-		PostID string `gorm:"column:post_id"`
-	}
-	if err := db.Table("likes").Where("user_id = ?", userID).Select("post_id").Find(&likedPosts).Error; err != nil {
-		return nil, err
+	var posts []models.Post
+	if err := PostQuery(database.DB, viewerID).Where("posts.id IN (?)", postIDs).Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch feed posts: %w", err)
 	}
-	postIDs := make([]string, len(likedPosts))
-	for i, post := range likedPosts {
-		postIDs[i] = post.PostID
+	byID := make(map[uuid.UUID]models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
 	}
-	log.Printf("Fetched liked post IDs: %v\n", postIDs)
-	return postIDs, nil
-}
 
-func GetEnhancedFeedPosts(userID uuid.UUID, connections, excludedPosts []string, limit, offset int) ([]models.Post, error) {
-	db := database.DB
-	log.Printf("Fetching filtered posts with userID: %s, connections: %v, excludedPosts: %v, limit: %d, offset: %d\n", userID, connections, excludedPosts, limit, offset)
-
-	var posts []models.Post
-
-	// Fetch user tags based on their posts
-	var userTags []string
-	if err := db.Table("post_tags").
-		Joins("JOIN tags ON post_tags.tag_id = tags.id").
-		Where("post_tags.post_id IN (?)", db.Table("posts").Select("id").Where("user_id = ?", userID)).
-		Select("DISTINCT tags.tag").
-		Find(&userTags).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch user tags: %w", err)
-	}
-
-	// Fetch user interests
-	var userInterests []string
-	if err := db.Table("user_interests").
-		Joins("JOIN interests ON user_interests.interest_id = interests.interest_id").
-		Where("user_interests.user_id = ?", userID).
-		Select("DISTINCT interests.interest_name").
-		Find(&userInterests).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch user interests: %w", err)
-	}
-
-	// Combine tags and interests for matching posts
-	combinedTagsAndInterests := append(userTags, userInterests...)
-	log.Printf("Combined tags and interests: %v", combinedTagsAndInterests)
-
-	// Fetch most liked posts
-	var mostLikedPostIDs []string
-	if err := db.Table("likes").
-		Select("post_id").
-		Group("post_id").
-		Order("COUNT(post_id) DESC").
-		Limit(3).
-		Find(&mostLikedPostIDs).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch most liked posts: %w", err)
-	}
-
-	// Fetch most commented posts
-	var mostCommentedPostIDs []string
-	if err := db.Table("comments").
-		Select("post_id").
-		Group("post_id").
-		Order("COUNT(post_id) DESC").
-		Limit(3).
-		Find(&mostCommentedPostIDs).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch most commented posts: %w", err)
-	}
-
-	// Combine liked and commented posts for weighted ranking
-	weightedPostIDs := append(mostLikedPostIDs, mostCommentedPostIDs...)
-
-	// Query for posts from connections, now including username and profile_pic_url
-	query := db.Table("posts").
-    Select(`posts.id, posts.user_id, posts.content, posts.media_url, 
-            COUNT(DISTINCT likes.user_id) AS likes_count, 
-            COUNT(DISTINCT comments.user_id) AS comments_count, 
-            posts.created_at, 
-            users.username, users.profile_pic_url`).
-    Joins("JOIN users ON posts.user_id = users.id").
-    Joins("LEFT JOIN likes ON likes.post_id = posts.id").
-    Joins("LEFT JOIN comments ON comments.post_id = posts.id").
-    Where("posts.user_id IN (?)", connections).
-    Group("posts.id, posts.user_id, posts.content, posts.media_url, posts.created_at, users.username, users.profile_pic_url")
-
-
-	// Exclude certain posts
-	if len(excludedPosts) > 0 {
-		query = query.Where("posts.id NOT IN (?)", excludedPosts)
-	}
-
-	// Fetch posts from connections
-	var connectionPosts []models.Post
-	if err := query.Order("posts.created_at DESC").Limit(limit).Offset(offset).Find(&connectionPosts).Error; err != nil {
-		return nil, fmt.Errorf("failed to fetch posts from connections: %w", err)
-	}
-
-	// Query for posts matching user tags and interests
-	var tagsAndInterestsPosts []models.Post
-	if len(combinedTagsAndInterests) > 0 {
-		tagsAndInterestsQuery := db.Table("posts").
-    Select(`posts.id, posts.user_id, posts.content, posts.media_url, 
-            COUNT(DISTINCT likes.user_id) AS likes_count, 
-            COUNT(DISTINCT comments.user_id) AS comments_count, 
-            posts.created_at, 
-            users.username, users.profile_pic_url`).
-    Where("posts.id NOT IN (?)", excludedPosts).
-    Where("posts.id IN (?)",
-        db.Table("post_tags").
-            Joins("JOIN tags ON post_tags.tag_id = tags.id").
-            Where("tags.tag IN (?)", combinedTagsAndInterests).
-            Select("post_tags.post_id")).
-    Joins("JOIN users ON posts.user_id = users.id").
-    Joins("LEFT JOIN likes ON likes.post_id = posts.id").
-    Joins("LEFT JOIN comments ON comments.post_id = posts.id").
-    Group("posts.id, posts.user_id, posts.content, posts.media_url, posts.created_at, users.username, users.profile_pic_url").
-    Order("posts.created_at DESC").
-    Limit(limit).
-    Offset(offset)
-
-
-
-		if err := tagsAndInterestsQuery.Find(&tagsAndInterestsPosts).Error; err != nil {
-			return nil, fmt.Errorf("failed to fetch posts matching tags: %w", err)
+	ordered := make([]models.Post, 0, len(ranked))
+	entries := make([]RankedPost, 0, len(ranked))
+	for _, entry := range ranked {
+		if post, ok := byID[entry.ID]; ok {
+			ordered = append(ordered, post)
+			entries = append(entries, entry)
 		}
 	}
-
-	// Query for posts based on weighted IDs (most liked and most commented)
-	var weightedPosts []models.Post
-	if len(weightedPostIDs) > 0 {
-		weightedQuery := db.Table("posts").
-    Select(`posts.id, posts.user_id, posts.content, posts.media_url, 
-            COUNT(DISTINCT likes.user_id) AS likes_count, 
-            COUNT(DISTINCT comments.user_id) AS comments_count, 
-            posts.created_at, 
-            users.username, users.profile_pic_url`).
-    Where("posts.id IN (?)", weightedPostIDs).
-    Joins("JOIN users ON posts.user_id = users.id").
-    Joins("LEFT JOIN likes ON likes.post_id = posts.id").
-    Joins("LEFT JOIN comments ON comments.post_id = posts.id").
-    Group("posts.id, posts.user_id, posts.content, posts.media_url, posts.created_at, users.username, users.profile_pic_url").
-    Order("posts.created_at DESC").
-    Limit(limit).
-    Offset(offset)
-
-
-
-		if err := weightedQuery.Find(&weightedPosts).Error; err != nil {
-			return nil, fmt.Errorf("failed to fetch weighted posts: %w", err)
+	feedPosts := BuildFeedPosts(ordered)
+	for i, entry := range entries {
+		score := entry.Score
+		feedPosts[i].PopularityScore = &score
+		if debug {
+			feedPosts[i].Ranking = &RankingDebug{Score: entry.Score, Sources: entry.Sources, Breakdown: entry.Breakdown}
 		}
 	}
+	return feedPosts, nil
+}
 
-	// Combine all post results
-	posts = append(posts, connectionPosts...)
-	posts = append(posts, tagsAndInterestsPosts...)
-	posts = append(posts, weightedPosts...)
-
-	// Deduplicate posts based on ID (avoid duplication across categories)
-	posts = deduplicatePosts(posts)
-	log.Printf("Retrieved filtered posts: %+v\n", posts)
-
-	return posts, nil
+// debugAllowed reports whether the viewer may see ranking breakdowns, which
+// expose how every scorer weighs them: moderators always, and everyone when
+// FEED_DEBUG is enabled for development.
+func debugAllowed(viewerID uuid.UUID) bool {
+	if enabled, _ := strconv.ParseBool(config.Config("FEED_DEBUG")); enabled {
+		return true
+	}
+	var moderator bool
+	if err := database.DB.Raw("SELECT EXISTS (SELECT 1 FROM moderators WHERE user_id = ?)", viewerID).Scan(&moderator).Error; err != nil {
+		log.Printf("Error checking moderator status: %v\n", err)
+		return false
+	}
+	return moderator
 }
 
+// BuildFeedPosts turns posts loaded with PostQuery into their API form with
+// tags, reaction and share counts, attachments, polls and link previews.
+func BuildFeedPosts(posts []models.Post) []FeedPost {
+	log.Println("Building feed posts and retrieving tags")
+	feedPosts := make([]FeedPost, len(posts))
 
-func deduplicatePosts(posts []models.Post) []models.Post {
-	seen := make(map[string]bool)
-	uniquePosts := []models.Post{}
+	postIDs := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	reactionCounts, err := RetrieveReactionCounts(postIDs)
+	if err != nil {
+		log.Printf("Error retrieving reaction counts: %v\n", err)
+	}
+	shareCounts, err := RetrieveShareCounts(postIDs)
+	if err != nil {
+		log.Printf("Error retrieving share counts: %v\n", err)
+	}
+	attachments, err := RetrieveAttachments(postIDs)
+	if err != nil {
+		log.Printf("Error retrieving attachments: %v\n", err)
+	}
+	polls, err := RetrievePolls(postIDs)
+	if err != nil {
+		log.Printf("Error retrieving polls: %v\n", err)
+	}
 
-	for _, post := range posts {
-		idStr := post.ID.String()
-		if !seen[idStr] {
-			seen[idStr] = true
-			uniquePosts = append(uniquePosts, post)
+	// Previews are shown for the first link of each post
+	links := make([]string, len(posts))
+	var urls []string
+	for i, post := range posts {
+		if links[i] = linkpreview.FirstURL(post.Content); links[i] != "" {
+			urls = append(urls, links[i])
 		}
 	}
-
-	return uniquePosts
-}
-
-func CalculatePopularityAndRetrieveTags(posts []models.Post) []FeedPost {
-	log.Println("Calculating popularity scores and retrieving tags")
-	feedPosts := make([]FeedPost, len(posts))
+	previews, err := linkpreview.Lookup(database.DB, urls)
+	if err != nil {
+		log.Printf("Error retrieving link previews: %v\n", err)
+	}
 
 	for i, post := range posts {
 		tags, err := RetrieveTagsForPost(post.ID.String())
@@ -292,8 +279,11 @@ func CalculatePopularityAndRetrieveTags(posts []models.Post) []FeedPost {
 			tags = []string{}
 		}
 
-		score := CalculateScore(post.LikesCount, post.CommentsCount, post.CreatedAt)
-		log.Printf("Post ID: %s, Score: %.2f\n", post.ID.String(), score)
+		var repostOfID *string
+		if post.RepostOfID != nil {
+			id := post.RepostOfID.String()
+			repostOfID = &id
+		}
 
 		feedPosts[i] = FeedPost{
 			ID:              post.ID.String(),
@@ -302,11 +292,20 @@ func CalculatePopularityAndRetrieveTags(posts []models.Post) []FeedPost {
 			ProfilePicURL:   post.ProfilePicURL,
 			Content:         post.Content,
 			MediaURL:        post.MediaURL,
+			CommunityID:     post.CommunityID,
+			PostType:        post.PostType,
+			Visibility:      post.Visibility,
+			RepostOfID:      repostOfID,
 			LikesCount:      post.LikesCount,
+			ReactionCounts:  reactionCounts[post.ID],
 			CommentsCount:   post.CommentsCount,
+			SharesCount:     shareCounts[post.ID],
 			Tags:            tags,
+			Attachments:     attachments[post.ID],
+			Poll:            polls[post.ID],
+			LinkPreview:     previews[links[i]],
 			CreatedAt:       post.CreatedAt,
-			PopularityScore: score,
+			EditedAt:        post.EditedAt,
 		}
 	}
 	return feedPosts
@@ -329,30 +328,156 @@ func RetrieveTagsForPost(postID string) ([]string, error) {
 	return tags, nil
 }
 
-func CalculateScore(likes, comments int, createdAt time.Time) float64 {
-	daysSincePost := time.Since(createdAt).Hours() / 24
-	if daysSincePost <= 0 {
-		daysSincePost = 1
+// RetrieveReactionCounts returns the number of reactions of each type per post.
+// Every requested post gets a non-nil map.
+func RetrieveReactionCounts(postIDs []uuid.UUID) (map[uuid.UUID]map[string]int, error) {
+	counts := make(map[uuid.UUID]map[string]int, len(postIDs))
+	for _, postID := range postIDs {
+		counts[postID] = map[string]int{}
+	}
+	if len(postIDs) == 0 {
+		return counts, nil
 	}
-	score := float64(likes*2+comments*3) / daysSincePost
-	log.Printf("Calculated score: %.2f (likes: %d, comments: %d, days since post: %.2f)\n", score, likes, comments, daysSincePost)
-	return score
+
+	var rows []struct {
+		PostID       uuid.UUID
+		ReactionType string
+		Count        int
+	}
+	if err := database.DB.Table("likes").
+		Select("post_id, reaction_type, COUNT(*) AS count").
+		Where("post_id IN (?)", postIDs).
+		Group("post_id, reaction_type").
+		Scan(&rows).Error; err != nil {
+		return counts, fmt.Errorf("error retrieving reaction counts: %w", err)
+	}
+	for _, row := range rows {
+		counts[row.PostID][row.ReactionType] = row.Count
+	}
+	return counts, nil
 }
 
-func SortByPopularity(posts []FeedPost) {
-	sort.Slice(posts, func(i, j int) bool {
-		return posts[i].PopularityScore > posts[j].PopularityScore
-	})
+// RecordImpressions counts the posts as shown to the viewer.
+func RecordImpressions(viewerID uuid.UUID, posts []FeedPost) {
+	postIDs := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		if postID, err := uuid.Parse(post.ID); err == nil {
+			postIDs = append(postIDs, postID)
+		}
+	}
+	analytics.RecordImpressions(viewerID, postIDs)
 }
 
-func ParsePagination(c *fiber.Ctx) (int, int) {
-	limit, err := strconv.Atoi(c.Query("limit", "10"))
-	if err != nil || limit <= 0 {
-		limit = 10
+// ApplyViewerState fills in the fields of posts that depend on who is viewing them.
+func ApplyViewerState(viewerID uuid.UUID, posts []FeedPost) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	postIDs := make([]string, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+
+	var bookmarkedIDs []string
+	if err := database.DB.Table("bookmarks").
+		Where("user_id = ? AND item_type = ? AND item_id IN (?)", viewerID, models.BookmarkItemPost, postIDs).
+		Pluck("item_id", &bookmarkedIDs).Error; err != nil {
+		return fmt.Errorf("error retrieving bookmarks: %w", err)
+	}
+	bookmarked := make(map[string]bool, len(bookmarkedIDs))
+	for _, id := range bookmarkedIDs {
+		bookmarked[id] = true
+	}
+	for i := range posts {
+		posts[i].Bookmarked = bookmarked[posts[i].ID]
+	}
+
+	if err := attachPollVotes(viewerID, posts); err != nil {
+		return err
+	}
+	return attachOriginalPosts(viewerID, posts)
+}
+
+// attachOriginalPosts loads the originals of reposts and quote posts that the
+// viewer may still see; originals that were deleted or hidden stay nil.
+func attachOriginalPosts(viewerID uuid.UUID, posts []FeedPost) error {
+	var originalIDs []string
+	for _, post := range posts {
+		if post.RepostOfID != nil {
+			originalIDs = append(originalIDs, *post.RepostOfID)
+		}
+	}
+	if len(originalIDs) == 0 {
+		return nil
+	}
+
+	var originals []OriginalPost
+	if err := database.DB.Table("posts").
+		Select("posts.id, posts.user_id, users.username, users.profile_pic_url, posts.content, posts.media_url, posts.post_type, posts.created_at").
+		Joins("JOIN users ON users.id = posts.user_id").
+		Where("posts.id IN (?)", originalIDs).
+		Where(VisiblePostsClause, viewerID).
+		Scan(&originals).Error; err != nil {
+		return fmt.Errorf("error retrieving original posts: %w", err)
+	}
+	byID := make(map[string]*OriginalPost, len(originals))
+	for i := range originals {
+		byID[originals[i].ID] = &originals[i]
+	}
+	for i := range posts {
+		if posts[i].RepostOfID != nil {
+			posts[i].OriginalPost = byID[*posts[i].RepostOfID]
+		}
+	}
+	return nil
+}
+
+// RetrieveShareCounts returns how often each post was shared directly, reposted
+// or quoted.
+func RetrieveShareCounts(postIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		PostID uuid.UUID
+		Count  int
+	}
+	query := `
+		SELECT post_id, COUNT(*) AS count FROM (
+			SELECT post_id FROM shares WHERE post_id IN (?)
+			UNION ALL
+			SELECT repost_of_id AS post_id FROM posts WHERE repost_of_id IN (?) AND deleted_at IS NULL
+		) AS all_shares
+		GROUP BY post_id
+	`
+	if err := database.DB.Raw(query, postIDs, postIDs).Scan(&rows).Error; err != nil {
+		return counts, fmt.Errorf("error retrieving share counts: %w", err)
+	}
+	for _, row := range rows {
+		counts[row.PostID] = row.Count
+	}
+	return counts, nil
+}
+
+// RetrieveAttachments returns the media attachments of each post in display
+// order. Posts without attachments map to an empty slice.
+func RetrieveAttachments(postIDs []uuid.UUID) (map[uuid.UUID][]models.PostAttachment, error) {
+	attachments := make(map[uuid.UUID][]models.PostAttachment, len(postIDs))
+	for _, id := range postIDs {
+		attachments[id] = []models.PostAttachment{}
+	}
+	if len(postIDs) == 0 {
+		return attachments, nil
+	}
+
+	var rows []models.PostAttachment
+	if err := database.DB.Where("post_id IN (?)", postIDs).Order("post_id, position").Find(&rows).Error; err != nil {
+		return attachments, fmt.Errorf("error retrieving attachments: %w", err)
 	}
-	offset, err := strconv.Atoi(c.Query("offset", "0"))
-	if err != nil || offset < 0 {
-		offset = 0
+	for _, row := range rows {
+		attachments[row.PostID] = append(attachments[row.PostID], row)
 	}
-	return limit, offset
+	return attachments, nil
 }
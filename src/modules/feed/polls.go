@@ -0,0 +1,120 @@
+package feed
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/models"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PollResult is a poll with its live vote counts. VotedOptionIDs holds the
+// options the viewer picked and is filled in by ApplyViewerState.
+type PollResult struct {
+	MultipleChoice bool               `json:"multiple_choice"`
+	ClosesAt       *time.Time         `json:"closes_at,omitempty"`
+	Closed         bool               `json:"closed"`
+	TotalVoters    int                `json:"total_voters"`
+	Options        []PollOptionResult `json:"options"`
+	VotedOptionIDs []int              `json:"voted_option_ids"`
+}
+
+// PollOptionResult is an option of a poll with the number of votes it received.
+type PollOptionResult struct {
+	ID    int    `json:"id"`
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// RetrievePolls returns the polls among the posts with their current counts.
+// Posts that are not polls have no entry.
+func RetrievePolls(postIDs []uuid.UUID) (map[uuid.UUID]*PollResult, error) {
+	results := make(map[uuid.UUID]*PollResult)
+	if len(postIDs) == 0 {
+		return results, nil
+	}
+
+	var polls []models.Poll
+	if err := database.DB.Where("post_id IN (?)", postIDs).Find(&polls).Error; err != nil {
+		return results, fmt.Errorf("error retrieving polls: %w", err)
+	}
+	if len(polls) == 0 {
+		return results, nil
+	}
+	pollIDs := make([]uuid.UUID, len(polls))
+	for i, poll := range polls {
+		pollIDs[i] = poll.PostID
+		results[poll.PostID] = &PollResult{
+			MultipleChoice: poll.MultipleChoice,
+			ClosesAt:       poll.ClosesAt,
+			Closed:         poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now()),
+			Options:        []PollOptionResult{},
+			VotedOptionIDs: []int{},
+		}
+	}
+
+	var options []struct {
+		ID     int
+		PostID uuid.UUID
+		Text   string
+		Votes  int
+	}
+	if err := database.DB.Table("poll_options").
+		Select("poll_options.id, poll_options.post_id, poll_options.text, COUNT(poll_votes.user_id) AS votes").
+		Joins("LEFT JOIN poll_votes ON poll_votes.option_id = poll_options.id").
+		Where("poll_options.post_id IN (?)", pollIDs).
+		Group("poll_options.id").
+		Order("poll_options.post_id, poll_options.position").
+		Scan(&options).Error; err != nil {
+		return results, fmt.Errorf("error retrieving poll options: %w", err)
+	}
+	for _, option := range options {
+		result := results[option.PostID]
+		result.Options = append(result.Options, PollOptionResult{ID: option.ID, Text: option.Text, Votes: option.Votes})
+	}
+
+	var voters []struct {
+		PostID uuid.UUID
+		Count  int
+	}
+	if err := database.DB.Table("poll_votes").
+		Select("post_id, COUNT(DISTINCT user_id) AS count").
+		Where("post_id IN (?)", pollIDs).
+		Group("post_id").
+		Scan(&voters).Error; err != nil {
+		return results, fmt.Errorf("error retrieving poll voters: %w", err)
+	}
+	for _, row := range voters {
+		results[row.PostID].TotalVoters = row.Count
+	}
+	return results, nil
+}
+
+// attachPollVotes marks the options the viewer voted for on each poll.
+func attachPollVotes(viewerID uuid.UUID, posts []FeedPost) error {
+	var pollIDs []string
+	for _, post := range posts {
+		if post.Poll != nil {
+			pollIDs = append(pollIDs, post.ID)
+		}
+	}
+	if len(pollIDs) == 0 {
+		return nil
+	}
+
+	var votes []models.PollVote
+	if err := database.DB.Where("user_id = ? AND post_id IN (?)", viewerID, pollIDs).Find(&votes).Error; err != nil {
+		return fmt.Errorf("error retrieving poll votes: %w", err)
+	}
+	voted := make(map[string][]int)
+	for _, vote := range votes {
+		voted[vote.PostID.String()] = append(voted[vote.PostID.String()], vote.OptionID)
+	}
+	for i := range posts {
+		if posts[i].Poll != nil && voted[posts[i].ID] != nil {
+			posts[i].Poll.VotedOptionIDs = voted[posts[i].ID]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,402 @@
+package feed
+
+import (
+	"Backend/src/core/config"
+	"Backend/src/core/database"
+	"Backend/src/core/models"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RankContext identifies a ranking run: whose feed it is and the time it is
+// computed as of. Activity after AsOf is ignored, so ranking again with the
+// same AsOf gives the same order, with two exceptions: tags are read as posts
+// carry them now, and interests and likes the viewer removed since are gone.
+// Neither is timestamped. Limit is the most posts a source may propose.
+type RankContext struct {
+	DB       *gorm.DB
+	ViewerID uuid.UUID
+	AsOf     time.Time
+	Limit    int
+}
+
+// CandidateSource proposes posts for a viewer's feed. Sources only return
+// posts the viewer may see, created by AsOf, that they have not liked, and at
+// most Limit of them.
+type CandidateSource interface {
+	Name() string
+	Candidates(rc RankContext) ([]uuid.UUID, error)
+}
+
+// Scorer rates a candidate for the viewer between 0 and 1. The pipeline
+// multiplies the rating by the scorer's weight.
+type Scorer interface {
+	Name() string
+	Score(viewer *ViewerProfile, candidate *Candidate) float64
+}
+
+// Candidate is a post being ranked, with its engagement as of the run's AsOf.
+type Candidate struct {
+	PostID    uuid.UUID
+	AuthorID  uuid.UUID
+	CreatedAt time.Time
+	Likes     int
+	Comments  int
+	Tags      []string
+	// Sources are the names of the sources that proposed the post
+	Sources []string
+}
+
+// ViewerProfile is what the scorers know about the viewer. Tags are the
+// lowercased tags of the viewer's posts and their interests; Interactions
+// counts the viewer's likes and comments on each candidate author's posts;
+// Recommendations holds the Recommender's scores for the candidates.
+type ViewerProfile struct {
+	ID              uuid.UUID
+	AsOf            time.Time
+	Tags            map[string]bool
+	Follows         map[uuid.UUID]bool
+	Interactions    map[uuid.UUID]int
+	Recommendations map[uuid.UUID]float64
+}
+
+// WeightedScorer is a scorer and how much it counts towards the final score.
+type WeightedScorer struct {
+	Scorer Scorer
+	Weight float64
+}
+
+// Pipeline ranks the candidates of all its sources by the weighted sum of its
+// scorers, breaking ties by post ID. Each source proposes at most
+// CandidatesPerSource posts, so the ranking covers a window of the posts the
+// viewer could see rather than all of them. Load fetches the proposed posts
+// and the viewer's profile, from the database as of AsOf when nil.
+type Pipeline struct {
+	Sources             []CandidateSource
+	Scorers             []WeightedScorer
+	CandidatesPerSource int
+	Load                func(rc RankContext, postIDs []uuid.UUID) ([]Candidate, *ViewerProfile, error)
+}
+
+// RankedPost is a feed post's position in the ranking. Breakdown holds each
+// scorer's weighted contribution to Score.
+type RankedPost struct {
+	ID        uuid.UUID
+	Score     float64
+	Sources   []string
+	Breakdown map[string]float64
+}
+
+// RankingDebug explains a post's place in the feed; see FetchFeed's ?debug.
+type RankingDebug struct {
+	Score     float64            `json:"score"`
+	Sources   []string           `json:"sources"`
+	Breakdown map[string]float64 `json:"breakdown"`
+}
+
+// Rank returns every candidate for the viewer, best first. capped reports
+// whether a source stopped at its limit, in which case posts the viewer could
+// see were left out of the ranking.
+func (p *Pipeline) Rank(rc RankContext) (ranked []RankedPost, capped bool, err error) {
+	rc.Limit = p.CandidatesPerSource
+	if rc.Limit <= 0 {
+		rc.Limit = defaultCandidatesPerSource
+	}
+	sources := make(map[uuid.UUID][]string)
+	var postIDs []uuid.UUID
+	for _, source := range p.Sources {
+		ids, err := source.Candidates(rc)
+		if err != nil {
+			return nil, false, fmt.Errorf("candidate source %s: %w", source.Name(), err)
+		}
+		if len(ids) >= rc.Limit {
+			capped = true
+		}
+		for _, id := range ids {
+			if _, seen := sources[id]; !seen {
+				postIDs = append(postIDs, id)
+			}
+			sources[id] = append(sources[id], source.Name())
+		}
+	}
+	if len(postIDs) == 0 {
+		return nil, false, nil
+	}
+
+	load := p.Load
+	if load == nil {
+		load = loadRankingData
+	}
+	candidates, viewer, err := load(rc, postIDs)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ranked = make([]RankedPost, len(candidates))
+	for i := range candidates {
+		candidate := &candidates[i]
+		candidate.Sources = sources[candidate.PostID]
+		entry := RankedPost{ID: candidate.PostID, Sources: candidate.Sources, Breakdown: make(map[string]float64, len(p.Scorers))}
+		for _, weighted := range p.Scorers {
+			contribution := weighted.Weight * weighted.Scorer.Score(viewer, candidate)
+			entry.Breakdown[weighted.Scorer.Name()] = contribution
+			entry.Score += contribution
+		}
+		ranked[i] = entry
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].ID.String() > ranked[j].ID.String()
+	})
+	return ranked, capped, nil
+}
+
+// after reports whether the post comes after the cursor in the ranking.
+func (r RankedPost) after(cursor *feedCursor) bool {
+	return r.Score < cursor.Score || (r.Score == cursor.Score && r.ID.String() < cursor.ID)
+}
+
+// loadRankingData fetches the candidates and the viewer's profile as of AsOf.
+func loadRankingData(rc RankContext, postIDs []uuid.UUID) ([]Candidate, *ViewerProfile, error) {
+	candidates, err := loadCandidates(rc, postIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	viewer, err := loadViewerProfile(rc, candidates)
+	if err != nil {
+		return nil, nil, err
+	}
+	return candidates, viewer, nil
+}
+
+// loadCandidates fetches the posts with their engagement as of AsOf.
+func loadCandidates(rc RankContext, postIDs []uuid.UUID) ([]Candidate, error) {
+	var candidates []Candidate
+	if err := rc.DB.Table("posts").
+		Select(`posts.id AS post_id, posts.user_id AS author_id, posts.created_at,
+			(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id AND likes.created_at <= ?) AS likes,
+			(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted_at IS NULL
+				AND comments.hidden_at IS NULL AND comments.created_at <= ?) AS comments`, rc.AsOf, rc.AsOf).
+		Where("posts.id IN (?)", postIDs).
+		Scan(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to load feed candidates: %w", err)
+	}
+
+	var tags []struct {
+		PostID uuid.UUID
+		Tag    string
+	}
+	if err := rc.DB.Table("post_tags").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Select("DISTINCT post_tags.post_id, LOWER(tags.tag) AS tag").
+		Where("post_tags.post_id IN (?)", postIDs).
+		Scan(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to load candidate tags: %w", err)
+	}
+	byPost := make(map[uuid.UUID][]string)
+	for _, row := range tags {
+		byPost[row.PostID] = append(byPost[row.PostID], row.Tag)
+	}
+	for i := range candidates {
+		candidates[i].Tags = byPost[candidates[i].PostID]
+	}
+	return candidates, nil
+}
+
+// loadViewerProfile fetches the viewer's tags, follows and interactions with
+// the candidates' authors as of AsOf.
+func loadViewerProfile(rc RankContext, candidates []Candidate) (*ViewerProfile, error) {
+	viewer := &ViewerProfile{
+		ID:              rc.ViewerID,
+		AsOf:            rc.AsOf,
+		Tags:            make(map[string]bool),
+		Follows:         make(map[uuid.UUID]bool),
+		Interactions:    make(map[uuid.UUID]int),
+		Recommendations: make(map[uuid.UUID]float64),
+	}
+
+	var tags []string
+	if err := rc.DB.Raw(`
+		SELECT LOWER(tags.tag) FROM post_tags
+		JOIN tags ON tags.id = post_tags.tag_id
+		WHERE post_tags.post_id IN (?)
+		UNION
+		(?)
+	`, viewerPosts(rc), viewerInterests(rc)).Scan(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to load viewer tags: %w", err)
+	}
+	for _, tag := range tags {
+		viewer.Tags[tag] = true
+	}
+
+	var follows []uuid.UUID
+	if err := rc.DB.Table("connections").
+		Where("user_id = ? AND created_at <= ?", rc.ViewerID, rc.AsOf).
+		Pluck("connection_id", &follows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load viewer follows: %w", err)
+	}
+	for _, id := range follows {
+		viewer.Follows[id] = true
+	}
+
+	authorSet := make(map[uuid.UUID]bool)
+	var authors []uuid.UUID
+	for _, candidate := range candidates {
+		if !authorSet[candidate.AuthorID] {
+			authorSet[candidate.AuthorID] = true
+			authors = append(authors, candidate.AuthorID)
+		}
+	}
+	var interactions []struct {
+		AuthorID uuid.UUID
+		Count    int
+	}
+	if err := rc.DB.Raw(`
+		SELECT posts.user_id AS author_id, COUNT(*) AS count FROM (
+			SELECT post_id FROM likes WHERE user_id = ? AND created_at <= ?
+			UNION ALL
+			SELECT post_id FROM comments WHERE user_id = ? AND created_at <= ? AND deleted_at IS NULL
+		) AS engaged
+		JOIN posts ON posts.id = engaged.post_id
+		WHERE posts.user_id IN (?)
+		GROUP BY posts.user_id
+	`, rc.ViewerID, rc.AsOf, rc.ViewerID, rc.AsOf, authors).Scan(&interactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load viewer interactions: %w", err)
+	}
+	for _, row := range interactions {
+		viewer.Interactions[row.AuthorID] = row.Count
+	}
+
+	postIDs := make([]uuid.UUID, len(candidates))
+	for i, candidate := range candidates {
+		postIDs[i] = candidate.PostID
+	}
+	var recommendations []models.PostRecommendation
+	if err := rc.DB.Where("user_id = ? AND computed_at = (?) AND post_id IN (?)", rc.ViewerID, latestRecommendations(rc), postIDs).
+		Find(&recommendations).Error; err != nil {
+		return nil, fmt.Errorf("failed to load viewer recommendations: %w", err)
+	}
+	for _, row := range recommendations {
+		viewer.Recommendations[row.PostID] = row.Score
+	}
+	return viewer, nil
+}
+
+// Ranking defaults, overridable through the environment by NewPipelineFromEnv
+const (
+	defaultRecencyWeight        = 1.0
+	defaultAffinityWeight       = 1.0
+	defaultTagMatchWeight       = 0.6
+	defaultEngagementWeight     = 0.8
+	defaultRecommendationWeight = 1.0
+	defaultRecencyHalfLife      = 24 * time.Hour
+	defaultCandidatesPerSource  = 200
+	defaultEngagementWindow     = 7 * 24 * time.Hour
+)
+
+// NewPipelineFromEnv builds the default pipeline: posts by followed users,
+// posts matching the viewer's tags and interests, recently popular posts and
+// the Recommender's picks, scored by recency, affinity to the author, tag
+// match, engagement and the recommendation score.
+//
+// FEED_WEIGHT_RECENCY, FEED_WEIGHT_AFFINITY, FEED_WEIGHT_TAG_MATCH,
+// FEED_WEIGHT_ENGAGEMENT and FEED_WEIGHT_RECOMMENDATION set the weights; FEED_RECENCY_HALF_LIFE_HOURS how
+// fast posts age; FEED_CANDIDATES_PER_SOURCE how many posts each source
+// proposes; FEED_ENGAGEMENT_WINDOW_DAYS how far back popular posts are found.
+func NewPipelineFromEnv() *Pipeline {
+	perSource := envInt("FEED_CANDIDATES_PER_SOURCE", defaultCandidatesPerSource)
+	if perSource == 0 {
+		perSource = defaultCandidatesPerSource
+	}
+	halfLife := defaultRecencyHalfLife
+	if hours := envFloat("FEED_RECENCY_HALF_LIFE_HOURS", 0); hours > 0 {
+		halfLife = time.Duration(hours * float64(time.Hour))
+	}
+	window := defaultEngagementWindow
+	if days := envInt("FEED_ENGAGEMENT_WINDOW_DAYS", 0); days > 0 {
+		window = time.Duration(days) * 24 * time.Hour
+	}
+
+	return &Pipeline{
+		Sources: []CandidateSource{
+			&FollowedSource{},
+			&TagMatchSource{},
+			&PopularSource{Window: window},
+			&RecommendedSource{},
+		},
+		CandidatesPerSource: perSource,
+		Scorers: []WeightedScorer{
+			{Scorer: &RecencyScorer{HalfLife: halfLife}, Weight: envFloat("FEED_WEIGHT_RECENCY", defaultRecencyWeight)},
+			{Scorer: &AffinityScorer{}, Weight: envFloat("FEED_WEIGHT_AFFINITY", defaultAffinityWeight)},
+			{Scorer: &TagMatchScorer{}, Weight: envFloat("FEED_WEIGHT_TAG_MATCH", defaultTagMatchWeight)},
+			{Scorer: &EngagementScorer{}, Weight: envFloat("FEED_WEIGHT_ENGAGEMENT", defaultEngagementWeight)},
+			{Scorer: &RecommendationScorer{}, Weight: envFloat("FEED_WEIGHT_RECOMMENDATION", defaultRecommendationWeight)},
+		},
+	}
+}
+
+// pipeline is built from the environment on first use; pipelineMu guards it,
+// as SetPipeline may swap it while feeds are being ranked.
+var (
+	pipelineMu sync.Mutex
+	pipeline   *Pipeline
+)
+
+// SetPipeline replaces the pipeline used by FetchFeed.
+func SetPipeline(p *Pipeline) {
+	pipelineMu.Lock()
+	defer pipelineMu.Unlock()
+	pipeline = p
+}
+
+func currentPipeline() *Pipeline {
+	pipelineMu.Lock()
+	defer pipelineMu.Unlock()
+	if pipeline == nil {
+		pipeline = NewPipelineFromEnv()
+	}
+	return pipeline
+}
+
+// RankFeedPosts returns up to limit feed posts for userID after cursor (from
+// the top when nil), ranked by the pipeline as of asOf. capped is Pipeline.Rank's.
+func RankFeedPosts(userID uuid.UUID, asOf time.Time, cursor *feedCursor, limit int) (ranked []RankedPost, capped bool, err error) {
+	ranked, capped, err = currentPipeline().Rank(RankContext{DB: database.DB, ViewerID: userID, AsOf: asOf})
+	if err != nil {
+		return nil, false, err
+	}
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(ranked), func(i int) bool { return ranked[i].after(cursor) })
+	}
+	ranked = ranked[start:]
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, capped, nil
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(config.Config(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+func envFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(config.Config(key)), 64)
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
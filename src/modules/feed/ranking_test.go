@@ -0,0 +1,116 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeLike is a like on a post, for fakeStore.
+type fakeLike struct {
+	postID uuid.UUID
+	at     time.Time
+}
+
+// fakeStore stands in for the database: posts and likes with the times they
+// were made, read as of the run's AsOf as the real sources and loaders do.
+type fakeStore struct {
+	posts map[uuid.UUID]time.Time
+	likes []fakeLike
+}
+
+func (s *fakeStore) Name() string { return "fake" }
+
+func (s *fakeStore) Candidates(rc RankContext) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for id, createdAt := range s.posts {
+		if !createdAt.After(rc.AsOf) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *fakeStore) load(rc RankContext, postIDs []uuid.UUID) ([]Candidate, *ViewerProfile, error) {
+	candidates := make([]Candidate, len(postIDs))
+	for i, id := range postIDs {
+		candidates[i] = Candidate{PostID: id, AuthorID: uuid.New(), CreatedAt: s.posts[id]}
+		for _, like := range s.likes {
+			if like.postID == id && !like.at.After(rc.AsOf) {
+				candidates[i].Likes++
+			}
+		}
+	}
+	return candidates, &ViewerProfile{ID: rc.ViewerID, AsOf: rc.AsOf}, nil
+}
+
+func TestRankFeedPostsPagesWithoutOverlap(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{posts: make(map[uuid.UUID]time.Time)}
+	var ids []uuid.UUID
+	for i := 0; i < 11; i++ {
+		id := uuid.New()
+		ids = append(ids, id)
+		// Pairs of posts share a creation time and like count, so they tie on score
+		store.posts[id] = asOf.Add(-time.Duration(i/2) * time.Hour)
+		for j := 0; j < i/2%3; j++ {
+			store.likes = append(store.likes, fakeLike{postID: id, at: asOf.Add(-time.Minute)})
+		}
+	}
+
+	SetPipeline(&Pipeline{
+		Sources: []CandidateSource{store},
+		Scorers: []WeightedScorer{
+			{Scorer: &RecencyScorer{HalfLife: 24 * time.Hour}, Weight: 1},
+			{Scorer: &EngagementScorer{}, Weight: 1},
+		},
+		Load: store.load,
+	})
+	defer SetPipeline(nil)
+
+	full, _, err := RankFeedPosts(uuid.New(), asOf, nil, len(ids))
+	if err != nil {
+		t.Fatalf("RankFeedPosts: %v", err)
+	}
+	if len(full) != len(ids) {
+		t.Fatalf("ranked %d posts, want %d", len(full), len(ids))
+	}
+
+	var paged []RankedPost
+	var cursor *feedCursor
+	for page := 0; page < len(ids); page++ {
+		ranked, _, err := RankFeedPosts(uuid.New(), asOf, cursor, 3)
+		if err != nil {
+			t.Fatalf("RankFeedPosts page %d: %v", page, err)
+		}
+		if len(ranked) == 0 {
+			break
+		}
+		paged = append(paged, ranked...)
+		last := ranked[len(ranked)-1]
+		cursor = &feedCursor{AsOf: asOf, Score: last.Score, ID: last.ID.String()}
+
+		// Activity between pages must not move posts across the cursor
+		store.posts[uuid.New()] = asOf.Add(time.Minute)
+		for _, id := range ids {
+			store.likes = append(store.likes, fakeLike{postID: id, at: asOf.Add(time.Duration(page+1) * time.Minute)})
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, post := range paged {
+		if seen[post.ID] {
+			t.Errorf("post %s appeared on more than one page", post.ID)
+		}
+		seen[post.ID] = true
+	}
+	if len(paged) != len(full) {
+		t.Fatalf("paged through %d posts, want %d", len(paged), len(full))
+	}
+	for i := range full {
+		if paged[i].ID != full[i].ID {
+			t.Errorf("position %d: paged %s, want %s", i, paged[i].ID, full[i].ID)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package feed
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/models"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Recommender defaults, overridable through the environment by NewRecommenderFromEnv
+const (
+	defaultRecommendInterval       = time.Hour
+	defaultRecommendEngagementDays = 30
+	defaultRecommendCandidateDays  = 14
+	defaultRecommendationsPerUser  = 100
+	defaultCoEngagementWeight      = 1.0
+	defaultTagOverlapWeight        = 0.6
+	defaultGraphWeight             = 0.8
+)
+
+const (
+	// Comments say more about interest in a post than likes
+	recommendLikeWeight    = 1.0
+	recommendCommentWeight = 1.5
+	// Engagers of very popular posts say little about taste and cost a lot
+	maxEngagersPerPost = 200
+	// Only the most similar users contribute co-engagement
+	maxSimilarUsers = 50
+	// Users followed by followed users count for less than direct follows
+	secondDegreeWeight = 0.5
+	// Users whose recommendations are computed and written together
+	recommendWriteBatch = 100
+)
+
+// Recommender precomputes personalised post recommendations from three
+// signals: co-engagement (posts liked or commented on by users who engaged
+// with the same posts), overlap between the post's tags and the user's tags
+// and interests, and the connection graph (posts engaged with by followed
+// users, and posts by users they follow).
+type Recommender struct {
+	DB *gorm.DB
+	// EngagementWindow is how far back likes and comments are considered
+	EngagementWindow time.Duration
+	// CandidateWindow is how old a recommended post may be
+	CandidateWindow time.Duration
+	PerUser         int
+
+	CoEngagementWeight float64
+	TagOverlapWeight   float64
+	GraphWeight        float64
+}
+
+// NewRecommenderFromEnv builds the recommender from FEED_RECOMMEND_ENGAGEMENT_DAYS,
+// FEED_RECOMMEND_CANDIDATE_DAYS, FEED_RECOMMENDATIONS_PER_USER and the signal
+// weights FEED_RECOMMEND_WEIGHT_CO_ENGAGEMENT, FEED_RECOMMEND_WEIGHT_TAGS and
+// FEED_RECOMMEND_WEIGHT_GRAPH.
+func NewRecommenderFromEnv(db *gorm.DB) *Recommender {
+	days := func(key string, fallback int) time.Duration {
+		value := envInt(key, fallback)
+		if value == 0 {
+			value = fallback
+		}
+		return time.Duration(value) * 24 * time.Hour
+	}
+	perUser := envInt("FEED_RECOMMENDATIONS_PER_USER", defaultRecommendationsPerUser)
+	if perUser == 0 {
+		perUser = defaultRecommendationsPerUser
+	}
+	return &Recommender{
+		DB:                 db,
+		EngagementWindow:   days("FEED_RECOMMEND_ENGAGEMENT_DAYS", defaultRecommendEngagementDays),
+		CandidateWindow:    days("FEED_RECOMMEND_CANDIDATE_DAYS", defaultRecommendCandidateDays),
+		PerUser:            perUser,
+		CoEngagementWeight: envFloat("FEED_RECOMMEND_WEIGHT_CO_ENGAGEMENT", defaultCoEngagementWeight),
+		TagOverlapWeight:   envFloat("FEED_RECOMMEND_WEIGHT_TAGS", defaultTagOverlapWeight),
+		GraphWeight:        envFloat("FEED_RECOMMEND_WEIGHT_GRAPH", defaultGraphWeight),
+	}
+}
+
+// recommendInterval is how often recommendations are recomputed, from
+// FEED_RECOMMEND_INTERVAL_MINUTES.
+func recommendInterval() time.Duration {
+	if minutes := envInt("FEED_RECOMMEND_INTERVAL_MINUTES", 0); minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return defaultRecommendInterval
+}
+
+// RefreshRecommendations recomputes every user's recommendations at startup
+// and then every FEED_RECOMMEND_INTERVAL_MINUTES. Runs are kept as long as a
+// feed cursor may refer to them.
+func RefreshRecommendations() {
+	interval := recommendInterval()
+	recommender := NewRecommenderFromEnv(database.DB)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		if err := recommender.Refresh(start, feedCursorTTL()); err != nil {
+			log.Printf("Error refreshing post recommendations: %v\n", err)
+		} else {
+			log.Printf("Refreshed post recommendations in %s\n", time.Since(start))
+		}
+		<-ticker.C
+	}
+}
+
+// Refresh computes a new run of recommendations stamped now, a batch of users
+// at a time, then deletes the runs a feed ranked as of now-retention or later
+// can no longer use: each user's runs superseded before that cutoff. Feeds read
+// a user's newest run, and each batch holds all of its users' rows in one
+// transaction, so a failed refresh never leaves a partial run to be served.
+func (r *Recommender) Refresh(now time.Time, retention time.Duration) error {
+	graph, err := r.loadGraph(now)
+	if err != nil {
+		return err
+	}
+
+	var after uuid.UUID
+	for {
+		var users []uuid.UUID
+		if err := r.DB.Table("users").Where("id > ?", after).Order("id").Limit(recommendWriteBatch).Pluck("id", &users).Error; err != nil {
+			return fmt.Errorf("failed to load users: %w", err)
+		}
+		if len(users) == 0 {
+			break
+		}
+		if err := r.loadUserSignals(graph, users, now); err != nil {
+			return err
+		}
+
+		var batch []models.PostRecommendation
+		for _, userID := range users {
+			for _, rec := range r.recommendFor(graph, userID) {
+				batch = append(batch, models.PostRecommendation{UserID: userID, PostID: rec.postID, ComputedAt: now, Score: rec.score})
+			}
+		}
+		if err := r.DB.Transaction(func(tx *gorm.DB) error { return writeRecommendations(tx, batch) }); err != nil {
+			return err
+		}
+		after = users[len(users)-1]
+	}
+
+	cutoff := now.Add(-retention)
+	return r.DB.Exec(`
+		DELETE FROM post_recommendations
+		WHERE computed_at < ? AND EXISTS (
+			SELECT 1 FROM post_recommendations newer
+			WHERE newer.user_id = post_recommendations.user_id
+				AND newer.computed_at > post_recommendations.computed_at AND newer.computed_at <= ?
+		)`, cutoff, cutoff).Error
+}
+
+func writeRecommendations(tx *gorm.DB, batch []models.PostRecommendation) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := tx.CreateInBatches(batch, 1000).Error; err != nil {
+		return fmt.Errorf("failed to store recommendations: %w", err)
+	}
+	return nil
+}
+
+// recommendationGraph is the engagement and candidate data of one run, plus
+// the tags and follows of the batch of users being recommended for.
+type recommendationGraph struct {
+	// engaged maps users to the weight of their likes and comments per post
+	engaged map[uuid.UUID]map[uuid.UUID]float64
+	// engagers maps posts to the users who engaged with them
+	engagers map[uuid.UUID][]uuid.UUID
+	// candidates are the recent posts that may be recommended, by author
+	candidates map[uuid.UUID]uuid.UUID
+	byAuthor   map[uuid.UUID][]uuid.UUID
+	postTags   map[uuid.UUID][]string
+	tagPosts   map[string][]uuid.UUID
+	userTags   map[uuid.UUID]map[string]bool
+	follows    map[uuid.UUID][]uuid.UUID
+}
+
+// loadGraph loads the engagement within EngagementWindow and the candidate
+// posts within CandidateWindow, which every user's recommendations draw on.
+func (r *Recommender) loadGraph(now time.Time) (*recommendationGraph, error) {
+	engagedSince := now.Add(-r.EngagementWindow)
+	candidateSince := now.Add(-r.CandidateWindow)
+	g := &recommendationGraph{
+		engaged:    make(map[uuid.UUID]map[uuid.UUID]float64),
+		engagers:   make(map[uuid.UUID][]uuid.UUID),
+		candidates: make(map[uuid.UUID]uuid.UUID),
+		byAuthor:   make(map[uuid.UUID][]uuid.UUID),
+		postTags:   make(map[uuid.UUID][]string),
+		tagPosts:   make(map[string][]uuid.UUID),
+	}
+
+	var edges []struct {
+		UserID uuid.UUID
+		PostID uuid.UUID
+		Weight float64
+	}
+	if err := r.DB.Raw(`
+		SELECT user_id, post_id, SUM(weight) AS weight FROM (
+			SELECT user_id, post_id, CAST(? AS double precision) AS weight FROM likes WHERE created_at >= ?
+			UNION ALL
+			SELECT user_id, post_id, CAST(? AS double precision) AS weight FROM comments
+			WHERE created_at >= ? AND deleted_at IS NULL AND hidden_at IS NULL
+		) AS engagement
+		GROUP BY user_id, post_id
+	`, recommendLikeWeight, engagedSince, recommendCommentWeight, engagedSince).Scan(&edges).Error; err != nil {
+		return nil, fmt.Errorf("failed to load engagement: %w", err)
+	}
+	for _, edge := range edges {
+		if g.engaged[edge.UserID] == nil {
+			g.engaged[edge.UserID] = make(map[uuid.UUID]float64)
+		}
+		g.engaged[edge.UserID][edge.PostID] = edge.Weight
+		g.engagers[edge.PostID] = append(g.engagers[edge.PostID], edge.UserID)
+	}
+
+	// Private posts and plain reposts are never worth recommending; the rest
+	// are checked against the viewer when the feed is served
+	candidatePosts := r.DB.Table("posts").
+		Where("deleted_at IS NULL AND hidden_at IS NULL AND created_at >= ?", candidateSince).
+		Where("post_type <> 'repost' AND visibility <> ?", models.PostVisibilityPrivate)
+	var posts []struct {
+		ID     uuid.UUID
+		UserID uuid.UUID
+	}
+	if err := candidatePosts.Session(&gorm.Session{}).Select("id, user_id").Scan(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load candidate posts: %w", err)
+	}
+	for _, post := range posts {
+		g.candidates[post.ID] = post.UserID
+		g.byAuthor[post.UserID] = append(g.byAuthor[post.UserID], post.ID)
+	}
+
+	var tags []struct {
+		PostID uuid.UUID
+		Tag    string
+	}
+	if err := r.DB.Table("post_tags").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Select("DISTINCT post_tags.post_id, LOWER(tags.tag) AS tag").
+		Where("post_tags.post_id IN (?)", candidatePosts.Session(&gorm.Session{}).Select("id")).
+		Scan(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to load candidate tags: %w", err)
+	}
+	for _, row := range tags {
+		g.postTags[row.PostID] = append(g.postTags[row.PostID], row.Tag)
+		g.tagPosts[row.Tag] = append(g.tagPosts[row.Tag], row.PostID)
+	}
+	return g, nil
+}
+
+// loadUserSignals replaces the graph's tags and follows with those of users,
+// along with the follows of the users they follow.
+func (r *Recommender) loadUserSignals(g *recommendationGraph, users []uuid.UUID, now time.Time) error {
+	g.userTags = make(map[uuid.UUID]map[string]bool)
+	g.follows = make(map[uuid.UUID][]uuid.UUID)
+
+	// A user's tags are those of their posts, their interests and the posts they engaged with
+	var userTags []struct {
+		UserID uuid.UUID
+		Tag    string
+	}
+	if err := r.DB.Raw(`
+		SELECT posts.user_id, LOWER(tags.tag) AS tag FROM post_tags
+		JOIN tags ON tags.id = post_tags.tag_id
+		JOIN posts ON posts.id = post_tags.post_id
+		WHERE posts.deleted_at IS NULL AND posts.user_id IN (?)
+		UNION
+		SELECT user_interests.user_id, LOWER(interests.interest_name) AS tag FROM user_interests
+		JOIN interests ON interests.interest_id = user_interests.interest_id
+		WHERE user_interests.user_id IN (?)
+		UNION
+		SELECT likes.user_id, LOWER(tags.tag) AS tag FROM likes
+		JOIN post_tags ON post_tags.post_id = likes.post_id
+		JOIN tags ON tags.id = post_tags.tag_id
+		WHERE likes.created_at >= ? AND likes.user_id IN (?)
+	`, users, users, now.Add(-r.EngagementWindow), users).Scan(&userTags).Error; err != nil {
+		return fmt.Errorf("failed to load user tags: %w", err)
+	}
+	for _, row := range userTags {
+		if g.userTags[row.UserID] == nil {
+			g.userTags[row.UserID] = make(map[string]bool)
+		}
+		g.userTags[row.UserID][row.Tag] = true
+	}
+
+	var follows []struct {
+		UserID       uuid.UUID
+		ConnectionID uuid.UUID
+	}
+	friends := r.DB.Table("connections").Select("connection_id").Where("user_id IN (?)", users)
+	if err := r.DB.Table("connections").Select("DISTINCT user_id, connection_id").
+		Where("user_id IN (?) OR user_id IN (?)", users, friends).
+		Scan(&follows).Error; err != nil {
+		return fmt.Errorf("failed to load connections: %w", err)
+	}
+	for _, row := range follows {
+		g.follows[row.UserID] = append(g.follows[row.UserID], row.ConnectionID)
+	}
+	return nil
+}
+
+type recommendation struct {
+	postID uuid.UUID
+	score  float64
+}
+
+// recommendFor scores the candidate posts for one user. Co-engagement and
+// graph signals are scaled by the user's best candidate, tag overlap is the
+// share of the post's tags the user has, and the weighted sum is scaled to
+// [0, 1]. Posts the user wrote or already engaged with are skipped.
+func (r *Recommender) recommendFor(g *recommendationGraph, userID uuid.UUID) []recommendation {
+	own := g.engaged[userID]
+	eligible := func(postID uuid.UUID) bool {
+		author, ok := g.candidates[postID]
+		_, engaged := own[postID]
+		return ok && author != userID && !engaged
+	}
+
+	// Co-engagement: cosine similarity of engagement with other users, then
+	// their engagement weighted by similarity
+	coEngagement := make(map[uuid.UUID]float64)
+	if len(own) > 0 {
+		overlap := make(map[uuid.UUID]float64)
+		for postID, weight := range own {
+			engagers := g.engagers[postID]
+			if len(engagers) > maxEngagersPerPost {
+				engagers = engagers[:maxEngagersPerPost]
+			}
+			for _, other := range engagers {
+				if other != userID {
+					overlap[other] += weight * g.engaged[other][postID]
+				}
+			}
+		}
+		norm := engagementNorm(own)
+		type similarUser struct {
+			userID     uuid.UUID
+			similarity float64
+		}
+		similar := make([]similarUser, 0, len(overlap))
+		for other, dot := range overlap {
+			similar = append(similar, similarUser{userID: other, similarity: dot / (norm * engagementNorm(g.engaged[other]))})
+		}
+		sort.Slice(similar, func(i, j int) bool {
+			if similar[i].similarity != similar[j].similarity {
+				return similar[i].similarity > similar[j].similarity
+			}
+			return similar[i].userID.String() < similar[j].userID.String()
+		})
+		if len(similar) > maxSimilarUsers {
+			similar = similar[:maxSimilarUsers]
+		}
+		for _, other := range similar {
+			for postID, weight := range g.engaged[other.userID] {
+				if eligible(postID) {
+					coEngagement[postID] += other.similarity * weight
+				}
+			}
+		}
+	}
+
+	// Connection graph: engagement of followed users and posts by the users they follow
+	graph := make(map[uuid.UUID]float64)
+	followed := make(map[uuid.UUID]bool, len(g.follows[userID]))
+	for _, id := range g.follows[userID] {
+		followed[id] = true
+	}
+	for _, friend := range g.follows[userID] {
+		for postID := range g.engaged[friend] {
+			if eligible(postID) {
+				graph[postID]++
+			}
+		}
+		for _, second := range g.follows[friend] {
+			if second == userID || followed[second] {
+				continue
+			}
+			for _, postID := range g.byAuthor[second] {
+				if eligible(postID) {
+					graph[postID] += secondDegreeWeight
+				}
+			}
+		}
+	}
+
+	// Tag overlap
+	tagHits := make(map[uuid.UUID]int)
+	for tag := range g.userTags[userID] {
+		for _, postID := range g.tagPosts[tag] {
+			if eligible(postID) {
+				tagHits[postID]++
+			}
+		}
+	}
+
+	maxCo, maxGraph := maxValue(coEngagement), maxValue(graph)
+	totalWeight := r.CoEngagementWeight + r.TagOverlapWeight + r.GraphWeight
+	if totalWeight == 0 {
+		return nil
+	}
+	scores := make(map[uuid.UUID]float64)
+	for postID, value := range coEngagement {
+		scores[postID] += r.CoEngagementWeight * value / maxCo
+	}
+	for postID, value := range graph {
+		scores[postID] += r.GraphWeight * value / maxGraph
+	}
+	for postID, hits := range tagHits {
+		scores[postID] += r.TagOverlapWeight * float64(hits) / float64(len(g.postTags[postID]))
+	}
+
+	recommendations := make([]recommendation, 0, len(scores))
+	for postID, score := range scores {
+		if score > 0 {
+			recommendations = append(recommendations, recommendation{postID: postID, score: score / totalWeight})
+		}
+	}
+	sortRecommendations(recommendations)
+	if len(recommendations) > r.PerUser {
+		recommendations = recommendations[:r.PerUser]
+	}
+	return recommendations
+}
+
+func engagementNorm(weights map[uuid.UUID]float64) float64 {
+	sum := 0.0
+	for _, weight := range weights {
+		sum += weight * weight
+	}
+	return math.Sqrt(sum)
+}
+
+func maxValue(values map[uuid.UUID]float64) float64 {
+	max := 0.0
+	for _, value := range values {
+		if value > max {
+			max = value
+		}
+	}
+	return max
+}
+
+// sortRecommendations orders by score, then ID, so equal scores keep a stable order.
+func sortRecommendations(recommendations []recommendation) {
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].score != recommendations[j].score {
+			return recommendations[i].score > recommendations[j].score
+		}
+		return recommendations[i].postID.String() < recommendations[j].postID.String()
+	})
+}
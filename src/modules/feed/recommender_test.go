@@ -0,0 +1,169 @@
+package feed
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	viewerUser   = uuid.MustParse("10000000-0000-0000-0000-000000000001")
+	similarUser  = uuid.MustParse("10000000-0000-0000-0000-000000000002")
+	followedUser = uuid.MustParse("10000000-0000-0000-0000-000000000003")
+	secondUser   = uuid.MustParse("10000000-0000-0000-0000-000000000004")
+	otherAuthor  = uuid.MustParse("10000000-0000-0000-0000-000000000005")
+
+	// best is engaged with by the similar and the followed user and carries
+	// only the viewer's tag, so it tops every signal
+	bestPost    = uuid.MustParse("20000000-0000-0000-0000-000000000001")
+	coPost      = uuid.MustParse("20000000-0000-0000-0000-000000000002")
+	graphPost   = uuid.MustParse("20000000-0000-0000-0000-000000000004")
+	secondPost  = uuid.MustParse("20000000-0000-0000-0000-000000000005")
+	tagPost     = uuid.MustParse("20000000-0000-0000-0000-000000000006")
+	sharedPost  = uuid.MustParse("20000000-0000-0000-0000-000000000007")
+	ownPost     = uuid.MustParse("20000000-0000-0000-0000-000000000008")
+	expiredPost = uuid.MustParse("20000000-0000-0000-0000-000000000009")
+)
+
+// testGraph builds the graph the recommender tests rank for viewerUser:
+//   - the viewer and the similar user both engaged with sharedPost, and the
+//     similar user also with bestPost, coPost, ownPost and expiredPost
+//   - the viewer follows followedUser, who engaged with bestPost and graphPost
+//     and follows the viewer and secondUser, the author of secondPost
+//   - the viewer's tag "go" is on bestPost and on half the tags of tagPost
+//   - expiredPost is not a candidate and ownPost is by the viewer
+func testGraph() *recommendationGraph {
+	g := &recommendationGraph{
+		engaged:    make(map[uuid.UUID]map[uuid.UUID]float64),
+		engagers:   make(map[uuid.UUID][]uuid.UUID),
+		candidates: make(map[uuid.UUID]uuid.UUID),
+		byAuthor:   make(map[uuid.UUID][]uuid.UUID),
+		postTags:   make(map[uuid.UUID][]string),
+		tagPosts:   make(map[string][]uuid.UUID),
+		userTags:   map[uuid.UUID]map[string]bool{viewerUser: {"go": true}},
+		follows: map[uuid.UUID][]uuid.UUID{
+			viewerUser:   {followedUser},
+			followedUser: {viewerUser, secondUser},
+		},
+	}
+	engage := func(userID uuid.UUID, postIDs ...uuid.UUID) {
+		if g.engaged[userID] == nil {
+			g.engaged[userID] = make(map[uuid.UUID]float64)
+		}
+		for _, postID := range postIDs {
+			g.engaged[userID][postID] = recommendLikeWeight
+			g.engagers[postID] = append(g.engagers[postID], userID)
+		}
+	}
+	candidate := func(postID, author uuid.UUID, tags ...string) {
+		g.candidates[postID] = author
+		g.byAuthor[author] = append(g.byAuthor[author], postID)
+		g.postTags[postID] = tags
+		for _, tag := range tags {
+			g.tagPosts[tag] = append(g.tagPosts[tag], postID)
+		}
+	}
+
+	candidate(bestPost, otherAuthor, "go")
+	candidate(coPost, otherAuthor)
+	candidate(graphPost, otherAuthor)
+	candidate(secondPost, secondUser)
+	candidate(tagPost, otherAuthor, "go", "rust")
+	candidate(sharedPost, otherAuthor, "go")
+	candidate(ownPost, viewerUser, "go")
+
+	engage(viewerUser, sharedPost)
+	engage(similarUser, sharedPost, bestPost, coPost, ownPost, expiredPost)
+	engage(followedUser, bestPost, graphPost)
+	return g
+}
+
+func TestRecommendFor(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Recommender
+		want []recommendation
+	}{
+		{
+			name: "all signals",
+			r:    Recommender{PerUser: 10, CoEngagementWeight: 1, TagOverlapWeight: 1, GraphWeight: 1},
+			want: []recommendation{
+				{bestPost, 1},
+				{coPost, 1.0 / 3},
+				{graphPost, 1.0 / 3},
+				{secondPost, 1.0 / 6},
+				{tagPost, 1.0 / 6},
+			},
+		},
+		{
+			name: "capped per user",
+			r:    Recommender{PerUser: 2, CoEngagementWeight: 1, TagOverlapWeight: 1, GraphWeight: 1},
+			want: []recommendation{
+				{bestPost, 1},
+				{coPost, 1.0 / 3},
+			},
+		},
+		{
+			name: "tags only",
+			r:    Recommender{PerUser: 10, TagOverlapWeight: 0.6},
+			want: []recommendation{
+				{bestPost, 1},
+				{tagPost, 0.5},
+			},
+		},
+		{
+			name: "weighted",
+			r:    Recommender{PerUser: 10, CoEngagementWeight: 2, GraphWeight: 1},
+			want: []recommendation{
+				{bestPost, 1},
+				{coPost, 2.0 / 3},
+				{graphPost, 1.0 / 3},
+				{secondPost, 1.0 / 6},
+			},
+		},
+		{
+			name: "no weight",
+			r:    Recommender{PerUser: 10},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.r.recommendFor(testGraph(), viewerUser)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d recommendations %v, want %v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if got[i].postID != tt.want[i].postID || math.Abs(got[i].score-tt.want[i].score) > epsilon {
+					t.Errorf("recommendation %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRecommendForWithoutSignals(t *testing.T) {
+	r := Recommender{PerUser: 10, CoEngagementWeight: 1, TagOverlapWeight: 1, GraphWeight: 1}
+	if got := r.recommendFor(testGraph(), uuid.New()); len(got) != 0 {
+		t.Errorf("a user with no engagement, tags or follows got %v", got)
+	}
+}
+
+func TestSortRecommendations(t *testing.T) {
+	recommendations := []recommendation{
+		{tagPost, 0.5},
+		{coPost, 0.9},
+		{graphPost, 0.5},
+		{bestPost, 0.5},
+	}
+	sortRecommendations(recommendations)
+
+	want := []uuid.UUID{coPost, bestPost, graphPost, tagPost}
+	for i, rec := range recommendations {
+		if rec.postID != want[i] {
+			t.Errorf("position %d = %s, want %s", i, rec.postID, want[i])
+		}
+	}
+}
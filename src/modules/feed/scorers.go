@@ -0,0 +1,89 @@
+package feed
+
+import (
+	"math"
+	"time"
+)
+
+// Engagement and interaction counts at which the scorers saturate
+const (
+	engagementSaturation  = 100
+	interactionSaturation = 20
+)
+
+// RecencyScorer halves a post's rating every HalfLife since it was created.
+type RecencyScorer struct {
+	HalfLife time.Duration
+}
+
+func (s *RecencyScorer) Name() string { return "recency" }
+
+func (s *RecencyScorer) Score(viewer *ViewerProfile, candidate *Candidate) float64 {
+	age := viewer.AsOf.Sub(candidate.CreatedAt)
+	if age < 0 {
+		age = 0
+	}
+	return math.Exp2(-float64(age) / float64(s.HalfLife))
+}
+
+// AffinityScorer rates how close the viewer is to the author: half for
+// following them, half for liking and commenting on their posts.
+type AffinityScorer struct{}
+
+func (s *AffinityScorer) Name() string { return "affinity" }
+
+func (s *AffinityScorer) Score(viewer *ViewerProfile, candidate *Candidate) float64 {
+	if candidate.AuthorID == viewer.ID {
+		return 0
+	}
+	score := 0.0
+	if viewer.Follows[candidate.AuthorID] {
+		score += 0.5
+	}
+	return score + 0.5*saturate(viewer.Interactions[candidate.AuthorID], interactionSaturation)
+}
+
+// TagMatchScorer rates the share of the post's tags the viewer is interested in.
+type TagMatchScorer struct{}
+
+func (s *TagMatchScorer) Name() string { return "tag_match" }
+
+func (s *TagMatchScorer) Score(viewer *ViewerProfile, candidate *Candidate) float64 {
+	if len(candidate.Tags) == 0 {
+		return 0
+	}
+	matched := 0
+	for _, tag := range candidate.Tags {
+		if viewer.Tags[tag] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(candidate.Tags))
+}
+
+// EngagementScorer rates the post's likes and comments, comments counting
+// more, on a logarithmic scale.
+type EngagementScorer struct{}
+
+func (s *EngagementScorer) Name() string { return "engagement" }
+
+func (s *EngagementScorer) Score(viewer *ViewerProfile, candidate *Candidate) float64 {
+	return saturate(candidate.Likes*2+candidate.Comments*3, engagementSaturation)
+}
+
+// RecommendationScorer passes on the Recommender's score for the post.
+type RecommendationScorer struct{}
+
+func (s *RecommendationScorer) Name() string { return "recommendation" }
+
+func (s *RecommendationScorer) Score(viewer *ViewerProfile, candidate *Candidate) float64 {
+	return viewer.Recommendations[candidate.PostID]
+}
+
+// saturate maps a count to [0, 1] logarithmically, reaching 1 at limit.
+func saturate(count, limit int) float64 {
+	if count <= 0 {
+		return 0
+	}
+	return math.Min(1, math.Log1p(float64(count))/math.Log1p(float64(limit)))
+}
@@ -0,0 +1,254 @@
+package feed
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const epsilon = 1e-9
+
+func TestSaturate(t *testing.T) {
+	tests := []struct {
+		name         string
+		count, limit int
+		want         float64
+	}{
+		{"zero", 0, 100, 0},
+		{"negative", -5, 100, 0},
+		{"one", 1, 100, math.Log(2) / math.Log(101)},
+		{"part way", 10, 100, math.Log(11) / math.Log(101)},
+		{"at the limit", 100, 100, 1},
+		{"past the limit", 1000, 100, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := saturate(tt.count, tt.limit); math.Abs(got-tt.want) > epsilon {
+				t.Errorf("saturate(%d, %d) = %v, want %v", tt.count, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecencyScorer(t *testing.T) {
+	asOf := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	scorer := &RecencyScorer{HalfLife: 10 * time.Hour}
+	viewer := &ViewerProfile{AsOf: asOf}
+
+	tests := []struct {
+		name string
+		age  time.Duration
+		want float64
+	}{
+		{"new", 0, 1},
+		{"one half-life", 10 * time.Hour, 0.5},
+		{"two half-lives", 20 * time.Hour, 0.25},
+		{"half a half-life", 5 * time.Hour, 1 / math.Sqrt2},
+		{"created after AsOf", -time.Hour, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scorer.Score(viewer, &Candidate{CreatedAt: asOf.Add(-tt.age)})
+			if math.Abs(got-tt.want) > epsilon {
+				t.Errorf("score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagMatchScorer(t *testing.T) {
+	viewer := &ViewerProfile{Tags: map[string]bool{"go": true, "rust": true}}
+
+	tests := []struct {
+		name string
+		tags []string
+		want float64
+	}{
+		{"untagged", nil, 0},
+		{"no match", []string{"java"}, 0},
+		{"some match", []string{"go", "java", "python", "c"}, 0.25},
+		{"half match", []string{"rust", "java"}, 0.5},
+		{"all match", []string{"go", "rust"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&TagMatchScorer{}).Score(viewer, &Candidate{Tags: tt.tags}); math.Abs(got-tt.want) > epsilon {
+				t.Errorf("score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAffinityScorer(t *testing.T) {
+	viewerID, followed, engaged, stranger := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	viewer := &ViewerProfile{
+		ID:           viewerID,
+		Follows:      map[uuid.UUID]bool{followed: true, viewerID: true},
+		Interactions: map[uuid.UUID]int{followed: interactionSaturation, engaged: 3, viewerID: 50},
+	}
+
+	tests := []struct {
+		name   string
+		author uuid.UUID
+		want   float64
+	}{
+		{"own post", viewerID, 0},
+		{"stranger", stranger, 0},
+		{"followed and engaged with", followed, 1},
+		{"engaged with only", engaged, 0.5 * saturate(3, interactionSaturation)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&AffinityScorer{}).Score(viewer, &Candidate{AuthorID: tt.author}); math.Abs(got-tt.want) > epsilon {
+				t.Errorf("score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngagementScorer(t *testing.T) {
+	tests := []struct {
+		name            string
+		likes, comments int
+		want            float64
+	}{
+		{"none", 0, 0, 0},
+		{"comments count more", 0, 2, saturate(6, engagementSaturation)},
+		{"likes", 3, 0, saturate(6, engagementSaturation)},
+		{"saturated", 40, 10, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&EngagementScorer{}).Score(&ViewerProfile{}, &Candidate{Likes: tt.likes, Comments: tt.comments})
+			if math.Abs(got-tt.want) > epsilon {
+				t.Errorf("score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fixedScorer rates posts from a table.
+type fixedScorer struct {
+	name   string
+	scores map[uuid.UUID]float64
+}
+
+func (s *fixedScorer) Name() string { return s.name }
+
+func (s *fixedScorer) Score(viewer *ViewerProfile, candidate *Candidate) float64 {
+	return s.scores[candidate.PostID]
+}
+
+// listSource proposes a fixed list of posts, or fails with err.
+type listSource struct {
+	name string
+	ids  []uuid.UUID
+	err  error
+}
+
+func (s *listSource) Name() string { return s.name }
+
+func (s *listSource) Candidates(rc RankContext) ([]uuid.UUID, error) { return s.ids, s.err }
+
+func loadBare(rc RankContext, postIDs []uuid.UUID) ([]Candidate, *ViewerProfile, error) {
+	candidates := make([]Candidate, len(postIDs))
+	for i, id := range postIDs {
+		candidates[i] = Candidate{PostID: id}
+	}
+	return candidates, &ViewerProfile{ID: rc.ViewerID, AsOf: rc.AsOf}, nil
+}
+
+func TestPipelineRank(t *testing.T) {
+	a := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	b := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+	c := uuid.MustParse("00000000-0000-0000-0000-00000000000c")
+	d := uuid.MustParse("00000000-0000-0000-0000-00000000000d")
+
+	pipeline := &Pipeline{
+		Sources: []CandidateSource{
+			&listSource{name: "first", ids: []uuid.UUID{a, b}},
+			&listSource{name: "second", ids: []uuid.UUID{b, c, d}},
+		},
+		Scorers: []WeightedScorer{
+			{Scorer: &fixedScorer{name: "x", scores: map[uuid.UUID]float64{a: 0.5, b: 1, c: 0.5, d: 0.25}}, Weight: 2},
+			{Scorer: &fixedScorer{name: "y", scores: map[uuid.UUID]float64{a: 0.5, c: 0.5, d: 1}}, Weight: 1},
+		},
+		CandidatesPerSource: 5,
+		Load:                loadBare,
+	}
+
+	ranked, capped, err := pipeline.Rank(RankContext{ViewerID: uuid.New(), AsOf: time.Now()})
+	if err != nil {
+		t.Fatalf("Rank: %v", err)
+	}
+	if capped {
+		t.Error("capped = true with every source under its limit")
+	}
+
+	// b scores 2; a, c and d tie at 1.5 and fall back to the post ID, highest first
+	wantOrder := []uuid.UUID{b, d, c, a}
+	var gotOrder []uuid.UUID
+	for _, post := range ranked {
+		gotOrder = append(gotOrder, post.ID)
+	}
+	if !reflect.DeepEqual(gotOrder, wantOrder) {
+		t.Fatalf("order = %v, want %v", gotOrder, wantOrder)
+	}
+
+	if got := ranked[0]; math.Abs(got.Score-2) > epsilon || got.Breakdown["x"] != 2 || got.Breakdown["y"] != 0 {
+		t.Errorf("b ranked with score %v and breakdown %v, want 2 from x alone", got.Score, got.Breakdown)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(ranked[0].Sources, want) {
+		t.Errorf("b sources = %v, want %v", ranked[0].Sources, want)
+	}
+	if want := []string{"second"}; !reflect.DeepEqual(ranked[1].Sources, want) {
+		t.Errorf("d sources = %v, want %v", ranked[1].Sources, want)
+	}
+}
+
+func TestPipelineRankCapped(t *testing.T) {
+	pipeline := &Pipeline{
+		Sources: []CandidateSource{
+			&listSource{name: "short", ids: []uuid.UUID{uuid.New()}},
+			&listSource{name: "full", ids: []uuid.UUID{uuid.New(), uuid.New()}},
+		},
+		CandidatesPerSource: 2,
+		Load:                loadBare,
+	}
+	if _, capped, err := pipeline.Rank(RankContext{}); err != nil || !capped {
+		t.Errorf("Rank = capped %v, error %v; want capped", capped, err)
+	}
+}
+
+func TestPipelineRankEmpty(t *testing.T) {
+	pipeline := &Pipeline{
+		Sources: []CandidateSource{&listSource{name: "empty"}},
+		Load: func(RankContext, []uuid.UUID) ([]Candidate, *ViewerProfile, error) {
+			t.Error("Load called without candidates")
+			return nil, nil, nil
+		},
+	}
+	if ranked, capped, err := pipeline.Rank(RankContext{}); err != nil || capped || len(ranked) != 0 {
+		t.Errorf("Rank = %v, capped %v, error %v; want nothing", ranked, capped, err)
+	}
+}
+
+func TestPipelineRankSourceError(t *testing.T) {
+	failure := errors.New("boom")
+	pipeline := &Pipeline{
+		Sources: []CandidateSource{&listSource{name: "broken", err: failure}},
+		Load:    loadBare,
+	}
+	if _, _, err := pipeline.Rank(RankContext{}); !errors.Is(err, failure) {
+		t.Errorf("error = %v, want %v", err, failure)
+	}
+}
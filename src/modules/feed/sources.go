@@ -0,0 +1,127 @@
+package feed
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// candidateQuery selects the IDs of posts any source may propose: visible to
+// the viewer, created by AsOf and not liked by the viewer by AsOf.
+func candidateQuery(rc RankContext) *gorm.DB {
+	return rc.DB.Table("posts").
+		Select("posts.id").
+		Where(VisiblePostsClause, rc.ViewerID).
+		Where("posts.created_at <= ?", rc.AsOf).
+		Where("posts.id NOT IN (?)", rc.DB.Table("likes").Select("post_id").Where("user_id = ? AND created_at <= ?", rc.ViewerID, rc.AsOf))
+}
+
+// viewerPosts selects the IDs of the posts the viewer had published at AsOf.
+func viewerPosts(rc RankContext) *gorm.DB {
+	return rc.DB.Table("posts").
+		Select("posts.id").
+		Where("posts.user_id = ? AND posts.created_at <= ?", rc.ViewerID, rc.AsOf).
+		Where("posts.deleted_at IS NULL OR posts.deleted_at > ?", rc.AsOf)
+}
+
+// viewerInterests selects the lowercased names of the interests the viewer had
+// picked at AsOf.
+func viewerInterests(rc RankContext) *gorm.DB {
+	return rc.DB.Table("user_interests").
+		Joins("JOIN interests ON user_interests.interest_id = interests.interest_id").
+		Where("user_interests.user_id = ? AND user_interests.created_at <= ?", rc.ViewerID, rc.AsOf).
+		Select("LOWER(interests.interest_name)")
+}
+
+// latestRecommendations selects when the viewer's newest recommendation run
+// at AsOf was computed.
+func latestRecommendations(rc RankContext) *gorm.DB {
+	return rc.DB.Table("post_recommendations").
+		Select("MAX(computed_at)").
+		Where("user_id = ? AND computed_at <= ?", rc.ViewerID, rc.AsOf)
+}
+
+// FollowedSource proposes the newest posts of the users the viewer follows.
+type FollowedSource struct{}
+
+func (s *FollowedSource) Name() string { return "followed" }
+
+func (s *FollowedSource) Candidates(rc RankContext) ([]uuid.UUID, error) {
+	followed := rc.DB.Table("connections").
+		Select("connection_id").
+		Where("user_id = ? AND created_at <= ?", rc.ViewerID, rc.AsOf)
+
+	var ids []uuid.UUID
+	err := candidateQuery(rc).
+		Where("posts.user_id IN (?)", followed).
+		Order("posts.created_at DESC, posts.id DESC").
+		Limit(rc.Limit).
+		Pluck("posts.id", &ids).Error
+	return ids, err
+}
+
+// TagMatchSource proposes the newest posts carrying a tag of the viewer's own
+// posts or one of their interests.
+type TagMatchSource struct{}
+
+func (s *TagMatchSource) Name() string { return "tag_match" }
+
+func (s *TagMatchSource) Candidates(rc RankContext) ([]uuid.UUID, error) {
+	userTags := rc.DB.Table("post_tags").
+		Joins("JOIN tags ON post_tags.tag_id = tags.id").
+		Where("post_tags.post_id IN (?)", viewerPosts(rc)).
+		Select("LOWER(tags.tag)")
+	taggedPosts := rc.DB.Table("post_tags").
+		Joins("JOIN tags ON post_tags.tag_id = tags.id").
+		Where("LOWER(tags.tag) IN (?) OR LOWER(tags.tag) IN (?)", userTags, viewerInterests(rc)).
+		Select("post_tags.post_id")
+
+	var ids []uuid.UUID
+	err := candidateQuery(rc).
+		Where("posts.user_id <> ?", rc.ViewerID).
+		Where("posts.id IN (?)", taggedPosts).
+		Order("posts.created_at DESC, posts.id DESC").
+		Limit(rc.Limit).
+		Pluck("posts.id", &ids).Error
+	return ids, err
+}
+
+// PopularSource proposes the most liked and commented posts created within
+// Window before AsOf, counting likes as 2 and comments as 3.
+type PopularSource struct {
+	Window time.Duration
+}
+
+func (s *PopularSource) Name() string { return "popular" }
+
+func (s *PopularSource) Candidates(rc RankContext) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := candidateQuery(rc).
+		Where("posts.created_at >= ?", rc.AsOf.Add(-s.Window)).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: `(SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id AND likes.created_at <= ?) * 2
+			+ (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted_at IS NULL
+				AND comments.hidden_at IS NULL AND comments.created_at <= ?) * 3 DESC, posts.id DESC`,
+			Vars: []interface{}{rc.AsOf, rc.AsOf}, WithoutParentheses: true}}).
+		Limit(rc.Limit).
+		Pluck("posts.id", &ids).Error
+	return ids, err
+}
+
+// RecommendedSource proposes the viewer's best recommendations from the
+// newest Recommender run at AsOf.
+type RecommendedSource struct{}
+
+func (s *RecommendedSource) Name() string { return "recommended" }
+
+func (s *RecommendedSource) Candidates(rc RankContext) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := candidateQuery(rc).
+		Joins("JOIN post_recommendations ON post_recommendations.post_id = posts.id").
+		Where("post_recommendations.user_id = ? AND post_recommendations.computed_at = (?)", rc.ViewerID, latestRecommendations(rc)).
+		Order("post_recommendations.score DESC, posts.id DESC").
+		Limit(rc.Limit).
+		Pluck("posts.id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,165 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Fetch limits
+const (
+	fetchTimeout = 5 * time.Second
+	maxBodySize  = 1 << 20
+	maxRedirects = 3
+	userAgent    = "Mozilla/5.0 (compatible; LinkPreviewBot/1.0)"
+)
+
+// ErrBlockedAddress is returned for URLs that resolve to private, loopback or
+// otherwise non-public addresses, or use a scheme or port other than http(s) on 80/443.
+var ErrBlockedAddress = errors.New("link preview target is not a public web address")
+
+// blockedPrefixes are the non-public ranges not covered by the netip predicates
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, embeds IPv4 addresses
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// isPublicAddr reports whether addr is a globally routable unicast address.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkURL rejects anything but http(s) URLs on the default ports.
+func checkURL(u *url.URL) error {
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return ErrBlockedAddress
+	}
+	if u.User != nil || u.Hostname() == "" {
+		return ErrBlockedAddress
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// newClient returns an HTTP client that only connects to addresses allowed by
+// isAllowed. The address is checked on the socket about to be connected, after
+// DNS resolution, so rebinding a hostname to an internal address between checks
+// does not help.
+func newClient(isAllowed func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: fetchTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isAllowed(addrPort.Addr()) {
+				return ErrBlockedAddress
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		Proxy:                 nil, // a proxy would connect on our behalf, bypassing the dialer check
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   fetchTimeout,
+		ResponseHeaderTimeout: fetchTimeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   fetchTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return checkURL(req.URL)
+		},
+	}
+}
+
+var client = newClient(isPublicAddr)
+
+// fetchPage downloads at most maxBodySize bytes of an HTML page and returns them
+// with the final URL after redirects.
+func fetchPage(ctx context.Context, rawURL string) ([]byte, *url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkURL(u); err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, nil, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+	if resp.ContentLength > maxBodySize {
+		return nil, nil, fmt.Errorf("page too large: %d bytes", resp.ContentLength)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp.Request.URL, nil
+}
+
+// normalizeURL trims punctuation commonly glued to links in prose and drops the fragment.
+func normalizeURL(raw string) (string, bool) {
+	raw = strings.TrimRight(raw, ".,;:!?)]}'\"")
+	u, err := url.Parse(raw)
+	if err != nil || checkURL(u) != nil {
+		return "", false
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), true
+}
@@ -0,0 +1,126 @@
+package linkpreview
+
+import (
+	"Backend/src/core/models"
+	"context"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Cache lifetimes of successful and failed fetches
+const (
+	previewTTL = 24 * time.Hour
+	failureTTL = time.Hour
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+var (
+	inflightMu sync.Mutex
+	inflight   = make(map[string]bool)
+)
+
+// FirstURL returns the first http(s) link in the content, normalized, or "" if
+// there is none.
+func FirstURL(content string) string {
+	for _, match := range urlPattern.FindAllString(content, 5) {
+		if u, ok := normalizeURL(match); ok {
+			return u
+		}
+	}
+	return ""
+}
+
+// Lookup returns the cached previews of the URLs. URLs without a successful
+// preview have no entry; stale and missing ones are refreshed in the background.
+func Lookup(db *gorm.DB, urls []string) (map[string]*models.LinkPreview, error) {
+	previews := make(map[string]*models.LinkPreview)
+	if len(urls) == 0 {
+		return previews, nil
+	}
+
+	var rows []models.LinkPreview
+	if err := db.Where("url IN (?)", urls).Find(&rows).Error; err != nil {
+		return previews, err
+	}
+	cached := make(map[string]bool, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		cached[row.URL] = true
+		if stale(row) {
+			go Refresh(db, row.URL)
+		}
+		if !row.Failed {
+			previews[row.URL] = row
+		}
+	}
+	for _, u := range urls {
+		if !cached[u] {
+			go Refresh(db, u)
+		}
+	}
+	return previews, nil
+}
+
+// Prefetch fetches the preview of the first link in the content unless a fresh
+// one is cached. It is meant to run in its own goroutine when a post is saved.
+func Prefetch(db *gorm.DB, content string) {
+	u := FirstURL(content)
+	if u == "" {
+		return
+	}
+	var existing models.LinkPreview
+	if err := db.Where("url = ?", u).Limit(1).Find(&existing).Error; err == nil && existing.URL != "" && !stale(&existing) {
+		return
+	}
+	Refresh(db, u)
+}
+
+// Refresh fetches the URL and caches its preview, or the failure. Concurrent
+// refreshes of the same URL are collapsed into one fetch.
+func Refresh(db *gorm.DB, u string) {
+	inflightMu.Lock()
+	if inflight[u] {
+		inflightMu.Unlock()
+		return
+	}
+	inflight[u] = true
+	inflightMu.Unlock()
+	defer func() {
+		inflightMu.Lock()
+		delete(inflight, u)
+		inflightMu.Unlock()
+	}()
+
+	preview := models.LinkPreview{URL: u, FetchedAt: time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	page, finalURL, err := fetchPage(ctx, u)
+	if err != nil {
+		log.Printf("Link preview fetch failed for %s: %v\n", u, err)
+		preview.Failed = true
+	} else {
+		meta := parseMetadata(page, finalURL)
+		preview.Title, preview.Description, preview.ImageURL, preview.SiteName = meta.Title, meta.Description, meta.ImageURL, meta.SiteName
+		// A page without a title has nothing worth rendering
+		preview.Failed = meta.Title == ""
+	}
+
+	if err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&preview).Error; err != nil {
+		log.Printf("Error caching link preview for %s: %v\n", u, err)
+	}
+}
+
+func stale(preview *models.LinkPreview) bool {
+	ttl := previewTTL
+	if preview.Failed {
+		ttl = failureTTL
+	}
+	return time.Since(preview.FetchedAt) > ttl
+}
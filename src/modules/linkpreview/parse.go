@@ -0,0 +1,105 @@
+package linkpreview
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Stored field lengths, in characters
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 500
+	maxSiteNameLength    = 100
+)
+
+// metadata is what a page says about itself.
+type metadata struct {
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// parseMetadata reads the OpenGraph and Twitter card tags of the page head,
+// falling back to <title> and the description meta tag. Relative image URLs
+// are resolved against base.
+func parseMetadata(page []byte, base *url.URL) metadata {
+	values := make(map[string]string)
+	var title string
+
+	tokenizer := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		if token.DataAtom == atom.Body {
+			break // metadata lives in the head
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		switch token.DataAtom {
+		case atom.Title:
+			if title == "" && tokenizer.Next() == html.TextToken {
+				title = string(tokenizer.Text())
+			}
+		case atom.Meta:
+			var key, content string
+			for _, attr := range token.Attr {
+				switch strings.ToLower(attr.Key) {
+				case "property", "name":
+					key = strings.ToLower(strings.TrimSpace(attr.Val))
+				case "content":
+					content = attr.Val
+				}
+			}
+			if key != "" && content != "" {
+				if _, seen := values[key]; !seen {
+					values[key] = content
+				}
+			}
+		}
+	}
+
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := strings.TrimSpace(values[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	meta := metadata{
+		Title:       truncate(first("og:title", "twitter:title"), maxTitleLength),
+		Description: truncate(first("og:description", "twitter:description", "description"), maxDescriptionLength),
+		SiteName:    truncate(first("og:site_name", "application-name"), maxSiteNameLength),
+	}
+	if meta.Title == "" {
+		meta.Title = truncate(strings.TrimSpace(title), maxTitleLength)
+	}
+	if image := first("og:image:secure_url", "og:image", "twitter:image", "twitter:image:src"); image != "" {
+		if ref, err := url.Parse(image); err == nil {
+			if resolved := base.ResolveReference(ref); checkURL(resolved) == nil {
+				meta.ImageURL = resolved.String()
+			}
+		}
+	}
+	return meta
+}
+
+// truncate collapses whitespace and cuts s to at most n characters.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,393 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// errUnknownDuration is returned when the container carries no readable duration.
+var errUnknownDuration = errors.New("duration not found")
+
+// probeDuration reads the playback length, in seconds, from the container headers.
+func probeDuration(contentType string, r io.ReaderAt, size int64) (float64, error) {
+	switch contentType {
+	case "video/mp4":
+		return mp4Duration(r, size)
+	case "video/webm":
+		return webmDuration(r, size)
+	case "audio/wave":
+		return wavDuration(r, size)
+	case "audio/mpeg":
+		return mp3Duration(r, size)
+	case "application/ogg":
+		return oggDuration(r, size)
+	}
+	return 0, errUnknownDuration
+}
+
+// mp4Duration walks the top-level boxes to moov/mvhd and reads its timescale and duration.
+func mp4Duration(r io.ReaderAt, size int64) (float64, error) {
+	moov, moovSize, err := findBox(r, 0, size, "moov")
+	if err != nil {
+		return 0, err
+	}
+	mvhd, _, err := findBox(r, moov, moov+moovSize, "mvhd")
+	if err != nil {
+		return 0, err
+	}
+
+	header := make([]byte, 32)
+	if _, err := r.ReadAt(header, mvhd); err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	var timescale, duration uint64
+	if header[0] == 1 {
+		timescale = uint64(binary.BigEndian.Uint32(header[20:]))
+		duration = binary.BigEndian.Uint64(header[24:])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(header[12:]))
+		duration = uint64(binary.BigEndian.Uint32(header[16:]))
+	}
+	if timescale == 0 {
+		return 0, errUnknownDuration
+	}
+	return float64(duration) / float64(timescale), nil
+}
+
+// findBox returns the payload offset and size of the first box of the given
+// type between start and end.
+func findBox(r io.ReaderAt, start, end int64, boxType string) (int64, int64, error) {
+	header := make([]byte, 16)
+	for pos := start; pos+8 <= end; {
+		if _, err := r.ReadAt(header[:8], pos); err != nil {
+			return 0, 0, err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = end - pos
+		case 1:
+			if _, err := r.ReadAt(header[8:16], pos+8); err != nil {
+				return 0, 0, err
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:]))
+			headerSize = 16
+		}
+		if boxSize < headerSize {
+			return 0, 0, errors.New("malformed mp4 box")
+		}
+		if string(header[4:8]) == boxType {
+			return pos + headerSize, boxSize - headerSize, nil
+		}
+		pos += boxSize
+	}
+	return 0, 0, errUnknownDuration
+}
+
+// wavDuration divides the data chunk length by the byte rate from the fmt chunk.
+func wavDuration(r io.ReaderAt, size int64) (float64, error) {
+	header := make([]byte, 8)
+	var byteRate uint32
+	for pos := int64(12); pos+8 <= size; {
+		if _, err := r.ReadAt(header, pos); err != nil {
+			return 0, err
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:]))
+		switch string(header[:4]) {
+		case "fmt ":
+			format := make([]byte, 16)
+			if _, err := r.ReadAt(format, pos+8); err != nil {
+				return 0, err
+			}
+			byteRate = binary.LittleEndian.Uint32(format[8:])
+		case "data":
+			if byteRate == 0 {
+				return 0, errUnknownDuration
+			}
+			return float64(min(chunkSize, size-pos-8)) / float64(byteRate), nil
+		}
+		pos += 8 + chunkSize + chunkSize%2
+	}
+	return 0, errUnknownDuration
+}
+
+// MPEG audio bitrates in kbit/s and sample rates in Hz, indexed by header bits
+var (
+	mp3Bitrates = map[bool][16]int{
+		true:  {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}, // MPEG-1 layer III
+		false: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},     // MPEG-2/2.5 layer III
+	}
+	mp3SampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// mp3Duration uses the frame count from a Xing/Info header when present and
+// otherwise estimates from the first frame's bitrate, assuming constant bitrate.
+func mp3Duration(r io.ReaderAt, size int64) (float64, error) {
+	start := int64(0)
+	id3 := make([]byte, 10)
+	if _, err := r.ReadAt(id3, 0); err == nil && string(id3[:3]) == "ID3" {
+		start = 10 + (int64(id3[6]&0x7f)<<21 | int64(id3[7]&0x7f)<<14 | int64(id3[8]&0x7f)<<7 | int64(id3[9]&0x7f))
+	}
+
+	buf := make([]byte, 4096)
+	n, err := r.ReadAt(buf, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xFF || buf[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		version := (buf[i+1] >> 3) & 0x03
+		layer := (buf[i+1] >> 1) & 0x03
+		rates, ok := mp3SampleRates[version]
+		if !ok || layer != 1 {
+			continue
+		}
+		rateIndex := (buf[i+2] >> 2) & 0x03
+		if rateIndex == 3 {
+			continue
+		}
+		mpeg1 := version == 3
+		bitrate := mp3Bitrates[mpeg1][buf[i+2]>>4] * 1000
+		sampleRate := rates[rateIndex]
+		if bitrate == 0 {
+			continue
+		}
+
+		samplesPerFrame := 1152
+		if !mpeg1 {
+			samplesPerFrame = 576
+		}
+		mono := buf[i+3]>>6 == 3
+		sideInfo := 32
+		switch {
+		case mpeg1 && mono:
+			sideInfo = 17
+		case !mpeg1 && mono:
+			sideInfo = 9
+		case !mpeg1:
+			sideInfo = 17
+		}
+
+		xing := i + 4 + sideInfo
+		if xing+12 <= len(buf) {
+			tag := string(buf[xing : xing+4])
+			if (tag == "Xing" || tag == "Info") && buf[xing+7]&0x01 != 0 {
+				frames := binary.BigEndian.Uint32(buf[xing+8:])
+				return float64(frames) * float64(samplesPerFrame) / float64(sampleRate), nil
+			}
+		}
+
+		audioBytes := size - start - int64(i)
+		return float64(audioBytes) * 8 / float64(bitrate), nil
+	}
+	return 0, errUnknownDuration
+}
+
+// Ogg page layout: a 27-byte header followed by the segment table
+const (
+	oggHeaderSize = 27
+	// Pages hold at most 255 segments of 255 bytes
+	oggMaxPageSize = oggHeaderSize + 255 + 255*255
+	// Opus granule positions always count 48 kHz samples
+	opusGranuleRate = 48000
+)
+
+// oggDuration divides the granule position of the stream's last page by the
+// sample rate from the Vorbis or Opus identification header on the first page,
+// less the Opus pre-skip. Other codecs have no known rate and no duration.
+func oggDuration(r io.ReaderAt, size int64) (float64, error) {
+	first := make([]byte, oggHeaderSize+255+19)
+	n, err := r.ReadAt(first, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	first = first[:n]
+	if len(first) < oggHeaderSize || string(first[:4]) != "OggS" {
+		return 0, errUnknownDuration
+	}
+	serial := binary.LittleEndian.Uint32(first[14:])
+	packet := first[min(oggHeaderSize+int(first[26]), len(first)):]
+
+	var rate, preSkip float64
+	switch {
+	case len(packet) >= 16 && string(packet[:7]) == "\x01vorbis":
+		rate = float64(binary.LittleEndian.Uint32(packet[12:]))
+	case len(packet) >= 12 && string(packet[:8]) == "OpusHead":
+		rate = opusGranuleRate
+		preSkip = float64(binary.LittleEndian.Uint16(packet[10:]))
+	}
+	if rate == 0 {
+		return 0, errUnknownDuration
+	}
+
+	// The last page starts within the final oggMaxPageSize bytes
+	start := max(size-oggMaxPageSize, 0)
+	tail := make([]byte, size-start)
+	n, err = r.ReadAt(tail, start)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	tail = tail[:n]
+	for pos := bytes.LastIndex(tail, []byte("OggS")); pos >= 0; pos = bytes.LastIndex(tail[:pos], []byte("OggS")) {
+		if pos+oggHeaderSize > len(tail) || binary.LittleEndian.Uint32(tail[pos+14:]) != serial {
+			continue
+		}
+		// A granule position of -1 marks a page on which no packet ends
+		granule := int64(binary.LittleEndian.Uint64(tail[pos+6:]))
+		if granule < 0 {
+			continue
+		}
+		return max(float64(granule)-preSkip, 0) / rate, nil
+	}
+	return 0, errUnknownDuration
+}
+
+// EBML element IDs needed to locate the segment duration
+const (
+	ebmlSegment       = 0x18538067
+	ebmlInfo          = 0x1549A966
+	ebmlTimecodeScale = 0x2AD7B1
+	ebmlDuration      = 0x4489
+)
+
+// webmDuration reads Segment/Info/Duration, scaled by TimecodeScale (nanoseconds per tick).
+func webmDuration(r io.ReaderAt, size int64) (float64, error) {
+	// The Info element sits near the start of the segment; bound the scan
+	limit := min(size, 1<<20)
+	buf := make([]byte, limit)
+	n, err := r.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	buf = buf[:n]
+
+	pos := 0
+	for pos < len(buf) {
+		id, idLen := readEBMLID(buf[pos:])
+		if idLen == 0 {
+			break
+		}
+		dataSize, sizeLen := readEBMLSize(buf[pos+idLen:])
+		if sizeLen == 0 {
+			break
+		}
+		body := pos + idLen + sizeLen
+
+		switch id {
+		case ebmlSegment:
+			pos = body // descend
+			continue
+		case ebmlInfo:
+			end := len(buf)
+			if dataSize >= 0 && body+int(dataSize) < end {
+				end = body + int(dataSize)
+			}
+			return parseWebMInfo(buf[body:end])
+		}
+		if dataSize < 0 {
+			break
+		}
+		pos = body + int(dataSize)
+	}
+	return 0, errUnknownDuration
+}
+
+func parseWebMInfo(info []byte) (float64, error) {
+	scale := uint64(1_000_000)
+	duration := -1.0
+	for pos := 0; pos < len(info); {
+		id, idLen := readEBMLID(info[pos:])
+		if idLen == 0 {
+			break
+		}
+		dataSize, sizeLen := readEBMLSize(info[pos+idLen:])
+		if sizeLen == 0 || dataSize < 0 {
+			break
+		}
+		body := pos + idLen + sizeLen
+		if body+int(dataSize) > len(info) {
+			break
+		}
+		data := info[body : body+int(dataSize)]
+
+		switch id {
+		case ebmlTimecodeScale:
+			var v uint64
+			for _, b := range data {
+				v = v<<8 | uint64(b)
+			}
+			if v > 0 {
+				scale = v
+			}
+		case ebmlDuration:
+			switch len(data) {
+			case 4:
+				duration = float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+			case 8:
+				duration = math.Float64frombits(binary.BigEndian.Uint64(data))
+			}
+		}
+		pos = body + int(dataSize)
+	}
+	if duration < 0 {
+		return 0, errUnknownDuration
+	}
+	return duration * float64(scale) / 1e9, nil
+}
+
+// readEBMLID returns an element ID with its length marker bits kept, as IDs are written.
+func readEBMLID(b []byte) (uint32, int) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0
+	}
+	length := 1
+	for mask := byte(0x80); b[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 4 || len(b) < length {
+		return 0, 0
+	}
+	var id uint32
+	for _, c := range b[:length] {
+		id = id<<8 | uint32(c)
+	}
+	return id, length
+}
+
+// readEBMLSize decodes a variable-length size; -1 means unknown size.
+func readEBMLSize(b []byte) (int64, int) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0
+	}
+	length := 1
+	mask := byte(0x80)
+	for b[0]&mask == 0 {
+		mask >>= 1
+		length++
+	}
+	if len(b) < length {
+		return 0, 0
+	}
+	value := int64(b[0] & (mask - 1))
+	allOnes := value == int64(mask-1)
+	for _, c := range b[1:length] {
+		value = value<<8 | int64(c)
+		allOnes = allOnes && c == 0xFF
+	}
+	if allOnes {
+		return -1, length
+	}
+	return value, length
+}
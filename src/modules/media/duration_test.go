@@ -0,0 +1,216 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// box builds an mp4 box around the payload.
+func box(boxType string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(out, boxType...), body...)
+}
+
+// mvhd builds a movie header payload of the given version.
+func mvhd(version byte, timescale uint32, duration uint64) []byte {
+	if version == 1 {
+		payload := make([]byte, 32)
+		payload[0] = 1
+		binary.BigEndian.PutUint32(payload[20:], timescale)
+		binary.BigEndian.PutUint64(payload[24:], duration)
+		return payload
+	}
+	payload := make([]byte, 20)
+	binary.BigEndian.PutUint32(payload[12:], timescale)
+	binary.BigEndian.PutUint32(payload[16:], uint32(duration))
+	return payload
+}
+
+// wav builds a RIFF file with the given byte rate and data length; data holds
+// only the bytes actually present, which may be fewer than declared.
+func wav(byteRate uint32, declared uint32, present int) []byte {
+	format := make([]byte, 16)
+	binary.LittleEndian.PutUint32(format[8:], byteRate)
+	out := []byte("RIFF\x00\x00\x00\x00WAVE")
+	out = append(out, "fmt "...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(format)))
+	out = append(out, format...)
+	out = append(out, "data"...)
+	out = binary.LittleEndian.AppendUint32(out, declared)
+	return append(out, make([]byte, present)...)
+}
+
+// mp3Frame is an MPEG-1 layer III stereo header at 128 kbit/s and 44.1 kHz.
+var mp3Frame = []byte{0xFF, 0xFB, 0x90, 0x00}
+
+// xingFrame is mp3Frame followed by its side information and a Xing header
+// carrying the frame count.
+func xingFrame(frames uint32) []byte {
+	out := append(append([]byte{}, mp3Frame...), make([]byte, 32)...)
+	out = append(out, "Xing\x00\x00\x00\x01"...)
+	return binary.BigEndian.AppendUint32(out, frames)
+}
+
+// id3 builds an ID3v2 tag with a syncsafe size and that many padding bytes.
+func id3(size int) []byte {
+	out := []byte{'I', 'D', '3', 4, 0, 0, byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}
+	return append(out, make([]byte, size)...)
+}
+
+// webm builds an EBML header and an unknown-size Segment holding an Info
+// element with the given children.
+func webm(info ...[]byte) []byte {
+	body := bytes.Join(info, nil)
+	out := []byte{0x1A, 0x45, 0xDF, 0xA3, 0x80}
+	out = append(out, 0x18, 0x53, 0x80, 0x67, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+	out = append(out, 0x15, 0x49, 0xA9, 0x66, 0x80|byte(len(body)))
+	return append(out, body...)
+}
+
+func float32Duration(v float32) []byte {
+	return binary.BigEndian.AppendUint32([]byte{0x44, 0x89, 0x84}, math.Float32bits(v))
+}
+
+func float64Duration(v float64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{0x44, 0x89, 0x88}, math.Float64bits(v))
+}
+
+// oggPage builds an Ogg page of one stream carrying the packet in a single
+// segment, which limits it to 255 bytes.
+func oggPage(serial uint32, granule int64, packet []byte) []byte {
+	out := []byte("OggS\x00\x00")
+	out = binary.LittleEndian.AppendUint64(out, uint64(granule))
+	out = binary.LittleEndian.AppendUint32(out, serial)
+	out = append(out, make([]byte, 8)...) // sequence number and checksum
+	out = append(out, 1, byte(len(packet)))
+	return append(out, packet...)
+}
+
+// vorbisHead builds a Vorbis identification header with the given sample rate.
+func vorbisHead(rate uint32) []byte {
+	out := append([]byte("\x01vorbis"), 0, 0, 0, 0, 2)
+	out = binary.LittleEndian.AppendUint32(out, rate)
+	return append(out, make([]byte, 14)...)
+}
+
+// opusHead builds an Opus identification header with the given pre-skip.
+func opusHead(preSkip uint16) []byte {
+	out := append([]byte("OpusHead"), 1, 2)
+	out = binary.LittleEndian.AppendUint16(out, preSkip)
+	return append(out, make([]byte, 7)...)
+}
+
+// ogg joins pages into a file.
+func ogg(pages ...[]byte) []byte {
+	return bytes.Join(pages, nil)
+}
+
+func TestProbeDuration(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		want        float64
+		wantErr     error
+	}{
+		{"mp4 version 0", "video/mp4", append(box("ftyp", make([]byte, 8)), box("moov", box("mvhd", mvhd(0, 1000, 5500)))...), 5.5, nil},
+		{"mp4 version 1", "video/mp4", box("moov", box("trak"), box("mvhd", mvhd(1, 600, 1800))), 3, nil},
+		{"mp4 without moov", "video/mp4", box("ftyp", make([]byte, 8)), 0, errUnknownDuration},
+		{"mp4 zero timescale", "video/mp4", box("moov", box("mvhd", mvhd(0, 0, 5500))), 0, errUnknownDuration},
+		{"wav", "audio/wave", wav(16000, 32000, 32000), 2, nil},
+		{"truncated wav", "audio/wave", wav(16000, 32000, 16000), 1, nil},
+		{"wav without byte rate", "audio/wave", wav(0, 32000, 32000), 0, errUnknownDuration},
+		{"constant bitrate mp3", "audio/mpeg", append(append([]byte{}, mp3Frame...), make([]byte, 15996)...), 1, nil},
+		{"mp3 after an id3 tag", "audio/mpeg", append(append(id3(300), mp3Frame...), make([]byte, 15996)...), 1, nil},
+		{"mp3 with a xing header", "audio/mpeg", append(xingFrame(441), make([]byte, 1000)...), 441 * 1152.0 / 44100, nil},
+		{"mp3 without frames", "audio/mpeg", make([]byte, 100), 0, errUnknownDuration},
+		{"webm with float32 duration", "video/webm", webm([]byte{0x2A, 0xD7, 0xB1, 0x83, 0x0F, 0x42, 0x40}, float32Duration(2500)), 2.5, nil},
+		{"webm with default scale", "video/webm", webm(float64Duration(1234.5)), 1.2345, nil},
+		{"webm with custom scale", "video/webm", webm([]byte{0x2A, 0xD7, 0xB1, 0x82, 0x27, 0x10}, float64Duration(300000)), 3, nil},
+		{"webm without duration", "video/webm", webm([]byte{0x2A, 0xD7, 0xB1, 0x83, 0x0F, 0x42, 0x40}), 0, errUnknownDuration},
+		{"vorbis", "application/ogg", ogg(oggPage(7, 0, vorbisHead(44100)), oggPage(7, 0, make([]byte, 40)), oggPage(7, 88200, make([]byte, 200))), 2, nil},
+		{"opus with pre-skip", "application/ogg", ogg(oggPage(7, 0, opusHead(312)), oggPage(7, 0, make([]byte, 40)), oggPage(7, 144312, make([]byte, 200))), 3, nil},
+		{"ogg longer than a page", "application/ogg", ogg(oggPage(7, 0, vorbisHead(8000)), bytes.Repeat(oggPage(7, 100, make([]byte, 255)), 300), oggPage(7, 16000, make([]byte, 40))), 2, nil},
+		{"ogg ending on an unfinished packet", "application/ogg", ogg(oggPage(7, 0, vorbisHead(8000)), oggPage(7, 4000, make([]byte, 40)), oggPage(7, -1, make([]byte, 255))), 0.5, nil},
+		{"ogg with a second stream last", "application/ogg", ogg(oggPage(7, 0, vorbisHead(8000)), oggPage(7, 8000, make([]byte, 40)), oggPage(9, 80000, make([]byte, 40))), 1, nil},
+		{"ogg of an unknown codec", "application/ogg", ogg(oggPage(7, 0, []byte("\x80theora")), oggPage(7, 8000, make([]byte, 40))), 0, errUnknownDuration},
+		{"ogg without pages", "application/ogg", []byte("not an ogg file"), 0, errUnknownDuration},
+		{"unsupported type", "image/png", []byte("data"), 0, errUnknownDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := probeDuration(tt.contentType, bytes.NewReader(tt.data), int64(len(tt.data)))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("duration = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadEBMLSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		wantValue int64
+		wantLen   int
+	}{
+		{"one byte", []byte{0x81}, 1, 1},
+		{"two bytes", []byte{0x40, 0x02}, 2, 2},
+		{"four bytes", []byte{0x10, 0x01, 0x00, 0x00}, 65536, 4},
+		{"unknown size", []byte{0xFF}, -1, 1},
+		{"unknown eight-byte size", []byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, -1, 8},
+		{"all ones in a longer encoding", []byte{0x7F, 0xFF}, -1, 2},
+		{"invalid marker", []byte{0x00}, 0, 0},
+		{"truncated", []byte{0x40}, 0, 0},
+		{"empty", nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, length := readEBMLSize(tt.data)
+			if value != tt.wantValue || length != tt.wantLen {
+				t.Errorf("readEBMLSize(% x) = (%d, %d), want (%d, %d)", tt.data, value, length, tt.wantValue, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestReadEBMLID(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantID  uint32
+		wantLen int
+	}{
+		{"one byte", []byte{0x81}, 0x81, 1},
+		{"two bytes", []byte{0x44, 0x89}, ebmlDuration, 2},
+		{"three bytes", []byte{0x2A, 0xD7, 0xB1}, ebmlTimecodeScale, 3},
+		{"four bytes", []byte{0x18, 0x53, 0x80, 0x67}, ebmlSegment, 4},
+		{"too long", []byte{0x08, 0x00, 0x00, 0x00, 0x00}, 0, 0},
+		{"truncated", []byte{0x15, 0x49}, 0, 0},
+		{"empty", nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, length := readEBMLID(tt.data)
+			if id != tt.wantID || length != tt.wantLen {
+				t.Errorf("readEBMLID(% x) = (%#x, %d), want (%#x, %d)", tt.data, id, length, tt.wantID, tt.wantLen)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder with image.Decode
+	"image/jpeg"
+	"image/png"
+)
+
+// Variant bounding boxes, in pixels
+const (
+	ThumbnailSize = 320
+	MediumSize    = 1080
+
+	maxImagePixels = 40_000_000
+	jpegQuality    = 88
+)
+
+var variantSizes = []struct {
+	name string
+	size int
+}{
+	{"thumbnail", ThumbnailSize},
+	{"medium", MediumSize},
+}
+
+// processImage decodes the image, applies and drops its EXIF orientation,
+// re-encodes the original without metadata and renders the resized variants.
+func processImage(processed *Processed) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(processed.Data))
+	if err != nil {
+		return fmt.Errorf("%w: invalid image: %v", ErrUnsupportedType, err)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return fmt.Errorf("%w: images may be at most %d megapixels", ErrTooLarge, maxImagePixels/1_000_000)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(processed.Data))
+	if err != nil {
+		return fmt.Errorf("%w: invalid image: %v", ErrUnsupportedType, err)
+	}
+
+	switch processed.ContentType {
+	case "image/jpeg":
+		img = applyOrientation(img, jpegOrientation(processed.Data))
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return err
+		}
+		processed.Data = buf.Bytes()
+	case "image/png":
+		// Re-encoding drops text and other ancillary chunks
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		processed.Data = buf.Bytes()
+	case "image/gif":
+		// GIFs carry no EXIF; keep the original so animations survive. Only the
+		// first frame is decoded, after the size check above, so a file with
+		// thousands of full-screen frames cannot exhaust memory here.
+	}
+
+	bounds := img.Bounds()
+	processed.Width, processed.Height = bounds.Dx(), bounds.Dy()
+
+	src := toRGBA(img)
+	for _, v := range variantSizes {
+		resized := fit(src, v.size)
+		variant := Variant{Name: v.name, Width: resized.Bounds().Dx(), Height: resized.Bounds().Dy()}
+
+		var buf bytes.Buffer
+		if processed.ContentType == "image/jpeg" {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: jpegQuality})
+			variant.ContentType, variant.Ext = "image/jpeg", ".jpg"
+		} else {
+			err = png.Encode(&buf, resized)
+			variant.ContentType, variant.Ext = "image/png", ".png"
+		}
+		if err != nil {
+			return err
+		}
+		variant.Data = buf.Bytes()
+		processed.Variants = append(processed.Variants, variant)
+	}
+	return nil
+}
+
+// fit scales img down, preserving its aspect ratio, so that neither side exceeds
+// size. Smaller images are returned unscaled.
+func fit(src *image.RGBA, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+	return resize(src, width, height)
+}
+
+// toRGBA copies img into an RGBA image anchored at the origin, the layout
+// resize reads its pixels from.
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
+
+// resize scales src to width x height by averaging the source pixels covered
+// by each destination pixel, which keeps downscaled images free of aliasing.
+func resize(src *image.RGBA, width, height int) *image.RGBA {
+	srcW, srcH := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcH/height, max((y+1)*srcH/height, y*srcH/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcW/width, max((x+1)*srcW/width, x*srcW/width+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(src.Pix[offset])
+					g += uint64(src.Pix[offset+1])
+					b += uint64(src.Pix[offset+2])
+					a += uint64(src.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// applyOrientation rotates and flips img so it displays upright once the EXIF
+// orientation tag is gone.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG, returning 1 when absent.
+func jpegOrientation(data []byte) int {
+	orientation, err := readJPEGOrientation(data)
+	if err != nil {
+		return 1
+	}
+	return orientation
+}
+
+func readJPEGOrientation(data []byte) (int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0, errors.New("not a jpeg")
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 0, errors.New("malformed jpeg")
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break // start of scan: no more metadata segments
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return 0, errors.New("malformed jpeg segment")
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return readTIFFOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 0, errors.New("no exif")
+}
+
+func readTIFFOrientation(tiff []byte) (int, error) {
+	if len(tiff) < 8 {
+		return 0, errors.New("short tiff header")
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, errors.New("bad tiff byte order")
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0, errors.New("bad ifd offset")
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:])), nil
+		}
+	}
+	return 0, errors.New("no orientation tag")
+}
@@ -0,0 +1,108 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Media types
+const (
+	TypeImage = "image"
+	TypeVideo = "video"
+	TypeAudio = "audio"
+)
+
+// Size limits per media type, in bytes
+const (
+	MaxImageSize = 10 << 20
+	MaxVideoSize = 100 << 20
+	MaxAudioSize = 25 << 20
+)
+
+// ErrUnsupportedType is returned for content that is not an accepted image, video or audio format.
+var ErrUnsupportedType = errors.New("unsupported media type")
+
+// ErrTooLarge is returned when a file exceeds the limit for its media type.
+var ErrTooLarge = errors.New("media file too large")
+
+// format describes an accepted content type.
+type format struct {
+	mediaType string
+	ext       string
+	maxSize   int
+}
+
+// formats maps sniffed content types to the media they are accepted as.
+var formats = map[string]format{
+	"image/jpeg":      {TypeImage, ".jpg", MaxImageSize},
+	"image/png":       {TypeImage, ".png", MaxImageSize},
+	"image/gif":       {TypeImage, ".gif", MaxImageSize},
+	"video/mp4":       {TypeVideo, ".mp4", MaxVideoSize},
+	"video/webm":      {TypeVideo, ".webm", MaxVideoSize},
+	"audio/mpeg":      {TypeAudio, ".mp3", MaxAudioSize},
+	"audio/wave":      {TypeAudio, ".wav", MaxAudioSize},
+	"application/ogg": {TypeAudio, ".ogg", MaxAudioSize},
+}
+
+// Variant is a resized rendition of an image.
+type Variant struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// Processed is a validated upload ready to be stored. Data is the sanitized
+// original; images have their metadata removed and come with resized variants.
+type Processed struct {
+	MediaType   string
+	ContentType string
+	Ext         string
+	Data        []byte
+	Width       int
+	Height      int
+	Duration    *float64
+	Variants    []Variant
+}
+
+// Process validates the upload by its content rather than its declared type or
+// name, then runs the pipeline for its media type.
+func Process(data []byte) (*Processed, error) {
+	contentType := strings.SplitN(http.DetectContentType(data), ";", 2)[0]
+	f, ok := formats[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+	if len(data) > f.maxSize {
+		return nil, fmt.Errorf("%w: %s files may be at most %d MB", ErrTooLarge, f.mediaType, f.maxSize>>20)
+	}
+
+	processed := &Processed{
+		MediaType:   f.mediaType,
+		ContentType: contentType,
+		Ext:         f.ext,
+		Data:        data,
+	}
+
+	switch f.mediaType {
+	case TypeImage:
+		if err := processImage(processed); err != nil {
+			return nil, err
+		}
+	default:
+		if duration, err := probeDuration(contentType, bytes.NewReader(data), int64(len(data))); err == nil {
+			processed.Duration = &duration
+		}
+	}
+	return processed, nil
+}
+
+// MaxSize is the largest upload accepted for any media type.
+func MaxSize() int {
+	return MaxVideoSize
+}
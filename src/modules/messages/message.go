@@ -5,7 +5,11 @@ import (
 	"Backend/src/core/database"
 	"Backend/src/core/helpers"
 	"Backend/src/core/models"
+	"Backend/src/modules/communities"
+	"Backend/src/modules/moderation"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
@@ -224,11 +228,13 @@ func ExtractUserIDFromJWT(c *fiber.Ctx) (string, error) {
 // 	log.Printf("WebSocket connection closed for community: %v", communityID)
 // }
 
+// WebSocketConnHandler relays a community's chat. The route authenticates the
+// upgrade with middleware.ProtectedSocket, so user_id is the verified caller.
 func WebSocketConnHandler(conn *websocket.Conn) {
-	// Extract userID from the query parameters
-	userIDStr := conn.Query("user_id")
+	userIDStr, _ := conn.Locals("user_id").(string)
 	if userIDStr == "" {
 		log.Println("user_id missing in WebSocket connection")
+		conn.Close()
 		return
 	}
 
@@ -248,6 +254,14 @@ func WebSocketConnHandler(conn *websocket.Conn) {
 	}
 	log.Printf("Community ID: %v", communityID)
 
+	// Only members of the community may join its chat
+	if _, err := communities.GetMembership(database.DB, communityID, userID); err != nil {
+		log.Printf("Rejecting WebSocket connection for user %v in community %v: %v", userID, communityID, err)
+		sendSocketError(conn, "You are not a member of this community")
+		conn.Close()
+		return
+	}
+
 	// Lock the shared map and add this connection to the communityConnections
 	mu.Lock()
 	log.Printf("Adding connection to community %v", communityIDStr)
@@ -270,6 +284,26 @@ func WebSocketConnHandler(conn *websocket.Conn) {
 		// Log the received message and message type
 		log.Printf("Received message from user %v in community %v: Type: %v, Message: %s", userID, communityID, msgType, string(msg))
 
+		// Re-check membership on every message so kicks, bans and mutes apply immediately
+		member, err := communities.CanSendMessage(database.DB, communityID, userID)
+		if errors.Is(err, communities.ErrMuted) {
+			sendSocketError(conn, fmt.Sprintf("You are muted until %s", member.MutedUntil.Format(time.RFC3339)))
+			continue
+		}
+		if err != nil {
+			log.Printf("User %v can no longer post in community %v: %v", userID, communityID, err)
+			sendSocketError(conn, "You are no longer a member of this community")
+			break
+		}
+
+		// Refuse blocked messages and star out masked words before anyone sees them
+		verdict := moderation.Screen(context.Background(), string(msg))
+		if verdict.Blocked() {
+			sendSocketError(conn, "Your message violates the community guidelines and was not sent")
+			continue
+		}
+		text := verdict.Text
+
 		// Fetch the username from the database using the userID
 		username, err := GetUsernameByID(userID)
 		if err != nil {
@@ -281,7 +315,7 @@ func WebSocketConnHandler(conn *websocket.Conn) {
 		message := &models.Message{
 			CommunityID: communityID,
 			UserID:      userID,
-			Message:     string(msg),
+			Message:     text,
 			CreatedAt:   time.Now(),
 		}
 		log.Printf("Prepared message for database: %+v", message)
@@ -293,6 +327,9 @@ func WebSocketConnHandler(conn *websocket.Conn) {
 				log.Printf("Error saving message to database: %v", err)
 			} else {
 				log.Printf("Message saved to database: %+v", message)
+				if verdict.Flagged() {
+					moderation.FlagContent(database.DB, models.ReportTargetMessage, strconv.Itoa(message.ID), userID, verdict.Labels)
+				}
 			}
 		}()
 
@@ -307,7 +344,7 @@ func WebSocketConnHandler(conn *websocket.Conn) {
 			// Create a JSON message to broadcast
 			broadcastMessage := map[string]interface{}{
 				"username":    username,
-				"message":     string(msg),
+				"message":     text,
 				"createdAt":   message.CreatedAt,
 				"communityID": communityID,
 			}
@@ -343,6 +380,16 @@ func WebSocketConnHandler(conn *websocket.Conn) {
 	log.Printf("WebSocket connection closed for community: %v", communityID)
 }
 
+// sendSocketError writes an error frame back to a single client. Writes to a
+// connection must not overlap, so it holds mu like the broadcast loop.
+func sendSocketError(conn *websocket.Conn, message string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := conn.WriteJSON(map[string]interface{}{"error": message}); err != nil {
+		log.Printf("Error sending error message to %v: %v", conn.RemoteAddr(), err)
+	}
+}
+
 func GetUsernameByID(userID uuid.UUID) (string, error) {
 	db := database.DB
 
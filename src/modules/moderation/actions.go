@@ -0,0 +1,236 @@
+package moderation
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/middleware"
+	"Backend/src/core/models"
+	"Backend/src/modules/notifications"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Suspension lengths, in hours
+const (
+	defaultSuspensionHours = 7 * 24
+	maxSuspensionHours     = 365 * 24
+)
+
+// TakeAction applies a moderator's decision to a case: hide, restore, delete,
+// warn, suspend or dismiss. Every decision is recorded in the audit trail.
+func TakeAction(c *fiber.Ctx) error {
+	db := database.DB
+
+	moderatorID, err := requireModerator(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+	moderationCase, err := loadCase(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+
+	var req struct {
+		Action        string `json:"action"`
+		Note          string `json:"note"`
+		DurationHours int    `json:"duration_hours"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+	}
+	req.Note = strings.TrimSpace(req.Note)
+
+	action := models.ModerationAction{
+		CaseID:       &moderationCase.ID,
+		ModeratorID:  &moderatorID,
+		Action:       req.Action,
+		TargetType:   moderationCase.TargetType,
+		TargetID:     moderationCase.TargetID,
+		TargetUserID: moderationCase.TargetUserID,
+		Note:         req.Note,
+	}
+	now := time.Now()
+	status := models.CaseStatusActioned
+	var notice string
+	afterCommit := func() {}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		switch req.Action {
+		case models.ModerationHide:
+			if _, err := setHidden(tx, moderationCase.TargetType, moderationCase.TargetID, true); err != nil {
+				return err
+			}
+			notice = fmt.Sprintf("Your %s was hidden for violating the community guidelines.", moderationCase.TargetType)
+		case models.ModerationRestore:
+			if _, err := setHidden(tx, moderationCase.TargetType, moderationCase.TargetID, false); err != nil {
+				return err
+			}
+			// Restoring reopens nothing; the case keeps the outcome it had
+			status = moderationCase.Status
+			if err := tx.Model(moderationCase).Update("auto_hidden", false).Error; err != nil {
+				return err
+			}
+		case models.ModerationDelete:
+			cleanup, err := deleteTarget(tx, moderationCase.TargetType, moderationCase.TargetID, now)
+			if err != nil {
+				return err
+			}
+			afterCommit = cleanup
+			notice = fmt.Sprintf("Your %s was removed for violating the community guidelines.", moderationCase.TargetType)
+		case models.ModerationWarn:
+			if moderationCase.TargetUserID == nil {
+				return fiber.NewError(fiber.StatusBadRequest, "The reported user no longer exists")
+			}
+			notice = "You received a warning from the moderators for violating the community guidelines."
+		case models.ModerationSuspend:
+			if moderationCase.TargetUserID == nil {
+				return fiber.NewError(fiber.StatusBadRequest, "The reported user no longer exists")
+			}
+			hours := req.DurationHours
+			if hours == 0 {
+				hours = defaultSuspensionHours
+			}
+			if hours < 1 || hours > maxSuspensionHours {
+				return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("duration_hours must be between 1 and %d", maxSuspensionHours))
+			}
+			until := now.Add(time.Duration(hours) * time.Hour)
+			if err := tx.Table("users").Where("id = ?", moderationCase.TargetUserID).Update("suspended_until", until).Error; err != nil {
+				return err
+			}
+			action.ExpiresAt = &until
+			notice = fmt.Sprintf("Your account is suspended until %s for violating the community guidelines.", until.UTC().Format(time.RFC1123))
+		case models.ModerationDismiss:
+			if moderationCase.AutoHidden {
+				if _, err := setHidden(tx, moderationCase.TargetType, moderationCase.TargetID, false); err != nil {
+					return err
+				}
+				if err := tx.Model(moderationCase).Update("auto_hidden", false).Error; err != nil {
+					return err
+				}
+			}
+			status = models.CaseStatusDismissed
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, "Action must be one of: hide, restore, delete, warn, suspend, dismiss")
+		}
+
+		if status != moderationCase.Status {
+			if err := tx.Model(moderationCase).Updates(map[string]interface{}{"status": status, "resolved_at": now}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&action).Error
+	})
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+	afterCommit()
+
+	if req.Action == models.ModerationSuspend {
+		middleware.ForgetSuspension(moderationCase.TargetUserID.String())
+	}
+	if notice != "" && moderationCase.TargetUserID != nil {
+		if req.Note != "" {
+			notice += " Note from the moderators: " + req.Note
+		}
+		go func(userID uuid.UUID) {
+			if err := notifications.NotifyUsers(db, []uuid.UUID{userID}, "moderation", "Moderation notice", notice); err != nil {
+				log.Printf("Error sending moderation notice to %s: %v\n", userID, err)
+			}
+		}(*moderationCase.TargetUserID)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Moderation action applied", action)
+}
+
+// GetAuditLog lists moderation actions, newest first. Filters: moderator_id,
+// case_id, target_type and target_id.
+func GetAuditLog(c *fiber.Ctx) error {
+	db := database.DB
+
+	if _, err := requireModerator(c, db); err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+	limit, offset := helpers.ParsePagination(c, 20, 100)
+
+	query := db.Model(&models.ModerationAction{})
+	if moderatorID := c.Query("moderator_id"); moderatorID != "" {
+		parsed, err := uuid.Parse(moderatorID)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid moderator ID format", err)
+		}
+		query = query.Where("moderator_id = ?", parsed)
+	}
+	if caseID := c.Query("case_id"); caseID != "" {
+		parsed, err := strconv.Atoi(caseID)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid case ID format", err)
+		}
+		query = query.Where("case_id = ?", parsed)
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		query = query.Where("target_id = ?", targetID)
+	}
+
+	actions := []models.ModerationAction{}
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&actions).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch audit log", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Audit log fetched successfully", fiber.Map{
+		"actions": actions,
+		"offset":  offset,
+		"limit":   limit,
+	})
+}
+
+// ContentRemover deletes posts and comments the way their authors' own
+// deletions do. The posts module provides it; this package cannot import it.
+type ContentRemover interface {
+	// RemovePost deletes the post inside tx and returns cleanup to run once
+	// tx has committed.
+	RemovePost(tx *gorm.DB, postID uuid.UUID, now time.Time) (cleanup func(), err error)
+	// RemoveComment deletes the comment and its replies inside tx.
+	RemoveComment(tx *gorm.DB, commentID uuid.UUID, now time.Time) error
+}
+
+var remover ContentRemover
+
+// SetContentRemover installs the remover used for moderator deletions.
+func SetContentRemover(r ContentRemover) {
+	remover = r
+}
+
+// deleteTarget removes reported content. Posts and comments go through the
+// content remover; messages have no soft delete. The returned cleanup must
+// run after tx commits.
+func deleteTarget(tx *gorm.DB, targetType, targetID string, now time.Time) (func(), error) {
+	noCleanup := func() {}
+	switch targetType {
+	case models.ReportTargetPost, models.ReportTargetComment:
+		if remover == nil {
+			return nil, errors.New("no content remover is configured")
+		}
+		id, err := uuid.Parse(targetID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid target ID format")
+		}
+		if targetType == models.ReportTargetPost {
+			return remover.RemovePost(tx, id, now)
+		}
+		return noCleanup, remover.RemoveComment(tx, id, now)
+	case models.ReportTargetMessage:
+		return noCleanup, tx.Where("id = ?", targetID).Delete(&models.Message{}).Error
+	}
+	return nil, fiber.NewError(fiber.StatusBadRequest, "Users cannot be deleted; suspend them instead")
+}
@@ -0,0 +1,87 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Classification is a classifier's opinion of a text: one of the filter
+// actions, and the labels that led to it.
+type Classification struct {
+	Action string   `json:"action"`
+	Labels []string `json:"labels"`
+}
+
+// Classifier judges text with a model outside the rule engine.
+type Classifier interface {
+	Classify(ctx context.Context, text string) (Classification, error)
+}
+
+// StubClassifier answers every text with Result, or fails with Err. Its zero
+// value allows everything, which is what runs when no classifier is configured.
+type StubClassifier struct {
+	Result Classification
+	Err    error
+}
+
+func (s *StubClassifier) Classify(ctx context.Context, text string) (Classification, error) {
+	if s.Err != nil {
+		return Classification{}, s.Err
+	}
+	result := s.Result
+	if result.Action == "" {
+		result.Action = FilterAllow
+	}
+	return result, nil
+}
+
+// HTTPClassifier calls a remote model that accepts {"text": ...} and answers
+// {"action": "allow"|"mask"|"flag"|"block", "labels": [...]}. A mask answer
+// from the model is treated as a flag, since it says nothing about what to mask.
+type HTTPClassifier struct {
+	URL    string
+	client *http.Client
+}
+
+// NewHTTPClassifier returns a classifier that gives each call timeout.
+func NewHTTPClassifier(url string, timeout time.Duration) *HTTPClassifier {
+	return &HTTPClassifier{URL: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (h *HTTPClassifier) Classify(ctx context.Context, text string) (Classification, error) {
+	requestBody, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return Classification{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(requestBody))
+	if err != nil {
+		return Classification{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Classification{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Classification{}, fmt.Errorf("moderation classifier responded with status: %d", resp.StatusCode)
+	}
+
+	var result Classification
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Classification{}, err
+	}
+	if filterSeverity(result.Action) < 0 {
+		return Classification{}, fmt.Errorf("moderation classifier returned unknown action %q", result.Action)
+	}
+	if result.Action == FilterMask {
+		result.Action = FilterFlag
+	}
+	return result, nil
+}
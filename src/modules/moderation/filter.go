@@ -0,0 +1,205 @@
+package moderation
+
+import (
+	"Backend/src/core/config"
+	"Backend/src/core/models"
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Filter actions, from least to most severe. Masked content is stored with
+// the matches starred out, flagged content is stored and queued for review,
+// and blocked content is refused.
+const (
+	FilterAllow = "allow"
+	FilterMask  = "mask"
+	FilterFlag  = "flag"
+	FilterBlock = "block"
+)
+
+// ErrContentBlocked is returned to authors whose content the filter refused.
+var ErrContentBlocked = fiber.NewError(fiber.StatusUnprocessableEntity, "This content violates the community guidelines")
+
+// Verdict is the filter's decision about a text. Text is what should be
+// stored: the input with any masked matches starred out.
+type Verdict struct {
+	Action string
+	Text   string
+	Labels []string
+}
+
+// Blocked reports whether the content must be refused.
+func (v Verdict) Blocked() bool { return v.Action == FilterBlock }
+
+// Flagged reports whether the content should be queued for review once stored.
+func (v Verdict) Flagged() bool { return v.Action == FilterFlag }
+
+// TextFilter runs content through the rule engine and then the classifier,
+// keeping the most severe outcome.
+type TextFilter struct {
+	Rules      *RuleEngine
+	Classifier Classifier
+}
+
+func (f *TextFilter) Screen(ctx context.Context, text string) Verdict {
+	verdict := Verdict{Action: FilterAllow, Text: text}
+	labels := make(map[string]bool)
+
+	if f.Rules != nil {
+		var masked [][2]int
+		for _, match := range f.Rules.match(text) {
+			verdict.Action = moreSevere(verdict.Action, match.action)
+			labels[match.label] = true
+			if match.action == FilterMask {
+				masked = append(masked, [2]int{match.start, match.end})
+			}
+		}
+		verdict.Text = maskRanges(text, masked)
+	}
+
+	if f.Classifier != nil {
+		result, err := f.Classifier.Classify(ctx, text)
+		if err != nil {
+			// The rules still apply; a classifier outage must not stop people posting
+			log.Printf("Moderation classifier failed: %v\n", err)
+		} else {
+			verdict.Action = moreSevere(verdict.Action, result.Action)
+			for _, label := range result.Labels {
+				labels[label] = true
+			}
+		}
+	}
+
+	for label := range labels {
+		verdict.Labels = append(verdict.Labels, label)
+	}
+	sort.Strings(verdict.Labels)
+	return verdict
+}
+
+// filter is built from the environment on first use; filterMu guards it, as
+// SetTextFilter may swap it while requests are being screened.
+var (
+	filterMu sync.Mutex
+	filter   *TextFilter
+)
+
+// NewTextFilterFromEnv builds the filter from MODERATION_RULES_FILE, a JSON
+// RuleSet (the built-in rules when unset), and MODERATION_CLASSIFIER_URL, the
+// external classifier (a stub allowing everything when unset), which gets
+// MODERATION_CLASSIFIER_TIMEOUT_MS per call. An unreadable rule file falls
+// back to the built-in rules.
+func NewTextFilterFromEnv() *TextFilter {
+	rules, err := LoadRuleEngine(config.Config("MODERATION_RULES_FILE"))
+	if err != nil {
+		log.Printf("Error loading moderation rules, using the built-in rules: %v\n", err)
+		if rules, err = NewRuleEngine(defaultRules); err != nil {
+			log.Fatalf("Built-in moderation rules are invalid: %v", err)
+		}
+	}
+
+	var classifier Classifier = &StubClassifier{}
+	if url := config.Config("MODERATION_CLASSIFIER_URL"); url != "" {
+		timeout := 2000
+		if value, err := strconv.Atoi(config.Config("MODERATION_CLASSIFIER_TIMEOUT_MS")); err == nil && value > 0 {
+			timeout = value
+		}
+		classifier = NewHTTPClassifier(url, time.Duration(timeout)*time.Millisecond)
+	}
+	return &TextFilter{Rules: rules, Classifier: classifier}
+}
+
+// SetTextFilter replaces the filter used by Screen.
+func SetTextFilter(f *TextFilter) {
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	filter = f
+}
+
+// Screen runs text through the configured filter.
+func Screen(ctx context.Context, text string) Verdict {
+	filterMu.Lock()
+	if filter == nil {
+		filter = NewTextFilterFromEnv()
+	}
+	current := filter
+	filterMu.Unlock()
+	return current.Screen(ctx, text)
+}
+
+// FlagContent queues stored content the filter flagged for review, opening a
+// case for it if needed. It is meant to run in its own goroutine.
+func FlagContent(db *gorm.DB, targetType, targetID string, authorID uuid.UUID, labels []string) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		caseID, err := openCase(tx, targetType, targetID, authorID, time.Now())
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.ModerationAction{
+			CaseID:       &caseID,
+			Action:       models.ModerationFlag,
+			TargetType:   targetType,
+			TargetID:     targetID,
+			TargetUserID: &authorID,
+			Note:         "Flagged by the text filter: " + strings.Join(labels, ", "),
+		}).Error
+	})
+	if err != nil {
+		log.Printf("Error flagging %s %s: %v\n", targetType, targetID, err)
+	}
+}
+
+// filterSeverity orders the filter actions; unknown actions are -1.
+func filterSeverity(action string) int {
+	switch action {
+	case FilterAllow:
+		return 0
+	case FilterMask:
+		return 1
+	case FilterFlag:
+		return 2
+	case FilterBlock:
+		return 3
+	}
+	return -1
+}
+
+func moreSevere(a, b string) string {
+	if filterSeverity(b) > filterSeverity(a) {
+		return b
+	}
+	return a
+}
+
+// maskRanges replaces every character inside the byte ranges with '*'.
+func maskRanges(text string, ranges [][2]int) string {
+	if len(ranges) == 0 {
+		return text
+	}
+	var b strings.Builder
+	b.Grow(len(text))
+	for i, r := range text {
+		masked := false
+		for _, span := range ranges {
+			if i >= span[0] && i < span[1] {
+				masked = true
+				break
+			}
+		}
+		if masked && r != ' ' {
+			b.WriteRune('*')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
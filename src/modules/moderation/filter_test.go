@@ -0,0 +1,103 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTextFilterScreen(t *testing.T) {
+	rules, err := NewRuleEngine(defaultRules)
+	if err != nil {
+		t.Fatalf("built-in rules: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		classifier *StubClassifier
+		text       string
+		wantAction string
+		wantText   string
+		wantLabels []string
+	}{
+		{"clean text", &StubClassifier{}, "lovely weather today", FilterAllow, "lovely weather today", nil},
+		{"masked word", &StubClassifier{}, "what the fuck", FilterMask, "what the ****", []string{"profanity"}},
+		{"word inside a longer word", &StubClassifier{}, "shitake mushrooms", FilterAllow, "shitake mushrooms", nil},
+		{"masked case-insensitively", &StubClassifier{}, "Shit happens", FilterMask, "**** happens", []string{"profanity"}},
+		{"flagged pattern", &StubClassifier{}, "get free bitcoin now", FilterFlag, "get free bitcoin now", []string{"scam"}},
+		{"rule for every language", &StubClassifier{}, "buy cheap followers", FilterFlag, "buy cheap followers", []string{"spam"}},
+		{"blocked pattern", &StubClassifier{}, "go kill yourself", FilterBlock, "go kill yourself", []string{"self_harm"}},
+		{"most severe rule wins", &StubClassifier{}, "shit, free crypto", FilterFlag, "****, free crypto", []string{"profanity", "scam"}},
+		{"language rules follow the script", &StubClassifier{}, "fuck это очень плохо", FilterAllow, "fuck это очень плохо", nil},
+		{
+			"classifier raises the action",
+			&StubClassifier{Result: Classification{Action: FilterFlag, Labels: []string{"harassment"}}},
+			"you again", FilterFlag, "you again", []string{"harassment"},
+		},
+		{
+			"classifier keeps the masking",
+			&StubClassifier{Result: Classification{Action: FilterBlock, Labels: []string{"hate"}}},
+			"shit", FilterBlock, "****", []string{"hate", "profanity"},
+		},
+		{
+			"classifier cannot lower the action",
+			&StubClassifier{Result: Classification{Action: FilterAllow}},
+			"go kill yourself", FilterBlock, "go kill yourself", []string{"self_harm"},
+		},
+		{
+			"rules apply when the classifier fails",
+			&StubClassifier{Err: errors.New("timeout")},
+			"shit", FilterMask, "****", []string{"profanity"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &TextFilter{Rules: rules, Classifier: tt.classifier}
+			verdict := f.Screen(context.Background(), tt.text)
+			if verdict.Action != tt.wantAction {
+				t.Errorf("action = %q, want %q", verdict.Action, tt.wantAction)
+			}
+			if verdict.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", verdict.Text, tt.wantText)
+			}
+			if !reflect.DeepEqual(verdict.Labels, tt.wantLabels) {
+				t.Errorf("labels = %v, want %v", verdict.Labels, tt.wantLabels)
+			}
+		})
+	}
+}
+
+func TestMaskRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		ranges [][2]int
+		want   string
+	}{
+		{"no ranges", "hello world", nil, "hello world"},
+		{"one range", "hello world", [][2]int{{0, 5}}, "***** world"},
+		{"spaces are kept", "ab cd", [][2]int{{0, 5}}, "** **"},
+		{"several ranges", "one two three", [][2]int{{0, 3}, {8, 13}}, "*** two *****"},
+		{"overlapping ranges", "abcdef", [][2]int{{0, 3}, {2, 4}}, "****ef"},
+		{"multibyte runes", "héllo", [][2]int{{1, 3}}, "h*llo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskRanges(tt.text, tt.ranges); got != tt.want {
+				t.Errorf("maskRanges(%q, %v) = %q, want %q", tt.text, tt.ranges, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenUsesTextFilter(t *testing.T) {
+	SetTextFilter(&TextFilter{Classifier: &StubClassifier{Result: Classification{Action: FilterBlock}}})
+	defer SetTextFilter(nil)
+
+	if verdict := Screen(context.Background(), "anything"); !verdict.Blocked() {
+		t.Errorf("Screen action = %q, want %q", verdict.Action, FilterBlock)
+	}
+}
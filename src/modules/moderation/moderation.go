@@ -0,0 +1,48 @@
+package moderation
+
+import (
+	"Backend/src/core/config"
+	"Backend/src/core/helpers"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// defaultAutoHideThreshold is the number of distinct reports after which
+// content is hidden pending review, unless MODERATION_AUTO_HIDE_THRESHOLD says
+// otherwise. A threshold of 0 turns automatic hiding off.
+const defaultAutoHideThreshold = 5
+
+// autoHideThreshold reads MODERATION_AUTO_HIDE_THRESHOLD.
+func autoHideThreshold() int {
+	if value := config.Config("MODERATION_AUTO_HIDE_THRESHOLD"); value != "" {
+		if threshold, err := strconv.Atoi(value); err == nil && threshold >= 0 {
+			return threshold
+		}
+	}
+	return defaultAutoHideThreshold
+}
+
+// requireModerator returns the caller's ID if they are a platform moderator.
+func requireModerator(c *fiber.Ctx, db *gorm.DB) (uuid.UUID, error) {
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	moderator, err := isModerator(db, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !moderator {
+		return uuid.Nil, fiber.NewError(fiber.StatusForbidden, "Only moderators can access the moderation queue")
+	}
+	return userID, nil
+}
+
+func isModerator(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var moderator bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM moderators WHERE user_id = ?)", userID).Scan(&moderator).Error
+	return moderator, err
+}
@@ -0,0 +1,238 @@
+package moderation
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CaseSummary is a queue entry: the case with the distinct reasons reported.
+type CaseSummary struct {
+	models.ModerationCase
+	Reasons    []string `json:"reasons" gorm:"-"`
+	ReasonList string   `json:"-"`
+}
+
+// GetCases lists moderation cases, most reported first. Filters: status
+// (default open, or "all"), target_type, reason and assigned (me, none or a
+// moderator ID).
+func GetCases(c *fiber.Ctx) error {
+	db := database.DB
+
+	moderatorID, err := requireModerator(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+	limit, offset := helpers.ParsePagination(c, 20, 100)
+
+	query := db.Table("moderation_cases")
+	switch status := c.Query("status", models.CaseStatusOpen); status {
+	case "all":
+	case models.CaseStatusOpen, models.CaseStatusActioned, models.CaseStatusDismissed:
+		query = query.Where("moderation_cases.status = ?", status)
+	default:
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid status filter", nil)
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("moderation_cases.target_type = ?", targetType)
+	}
+	if reason := c.Query("reason"); reason != "" {
+		if !isValidReason(reason) {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid reason filter", nil)
+		}
+		query = query.Where("EXISTS (SELECT 1 FROM content_reports r WHERE r.case_id = moderation_cases.id AND r.reason = ?)", reason)
+	}
+	switch assigned := c.Query("assigned"); assigned {
+	case "":
+	case "me":
+		query = query.Where("moderation_cases.assigned_to = ?", moderatorID)
+	case "none":
+		query = query.Where("moderation_cases.assigned_to IS NULL")
+	default:
+		assigneeID, err := uuid.Parse(assigned)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid assigned filter", err)
+		}
+		query = query.Where("moderation_cases.assigned_to = ?", assigneeID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to count cases", err)
+	}
+
+	cases := []CaseSummary{}
+	err = query.Select(`moderation_cases.*,
+			(SELECT string_agg(DISTINCT r.reason, ',') FROM content_reports r WHERE r.case_id = moderation_cases.id) AS reason_list`).
+		Order("moderation_cases.report_count DESC, moderation_cases.created_at ASC").
+		Limit(limit).Offset(offset).
+		Scan(&cases).Error
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch cases", err)
+	}
+	for i := range cases {
+		cases[i].Reasons = []string{}
+		if cases[i].ReasonList != "" {
+			cases[i].Reasons = strings.Split(cases[i].ReasonList, ",")
+		}
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Cases fetched successfully", fiber.Map{
+		"cases":  cases,
+		"offset": offset,
+		"limit":  limit,
+		"total":  total,
+	})
+}
+
+// GetCase returns a case with its reports, its audit trail and a snapshot of the target.
+func GetCase(c *fiber.Ctx) error {
+	db := database.DB
+
+	if _, err := requireModerator(c, db); err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+	moderationCase, err := loadCase(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+
+	reports := []models.ContentReport{}
+	if err := db.Where("case_id = ?", moderationCase.ID).Order("created_at ASC").Find(&reports).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch reports", err)
+	}
+	actions := []models.ModerationAction{}
+	if err := db.Where("case_id = ?", moderationCase.ID).Order("created_at ASC, id ASC").Find(&actions).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch case history", err)
+	}
+	target, err := targetSnapshot(db, moderationCase.TargetType, moderationCase.TargetID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch reported content", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Case fetched successfully", fiber.Map{
+		"case":    moderationCase,
+		"reports": reports,
+		"actions": actions,
+		"target":  target,
+	})
+}
+
+// AssignCase assigns the case to the moderator in the body, or to the caller
+// when none is given. An explicit null moderator_id unassigns it.
+func AssignCase(c *fiber.Ctx) error {
+	db := database.DB
+
+	moderatorID, err := requireModerator(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+	moderationCase, err := loadCase(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+
+	// moderator_id: absent assigns the caller, null unassigns
+	assignee := &moderatorID
+	if len(c.Body()) > 0 {
+		var req map[string]interface{}
+		if err := c.BodyParser(&req); err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+		}
+		if value, present := req["moderator_id"]; present {
+			if value == nil {
+				assignee = nil
+			} else if raw, ok := value.(string); !ok {
+				return helpers.HandleError(c, fiber.StatusBadRequest, "moderator_id must be a string or null", nil)
+			} else {
+				parsed, err := uuid.Parse(raw)
+				if err != nil {
+					return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid moderator ID format", err)
+				}
+				moderator, err := isModerator(db, parsed)
+				if err != nil {
+					return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to check moderator", err)
+				}
+				if !moderator {
+					return helpers.HandleError(c, fiber.StatusBadRequest, "Cases can only be assigned to moderators", nil)
+				}
+				assignee = &parsed
+			}
+		}
+	}
+
+	note := "Unassigned"
+	if assignee != nil {
+		note = "Assigned to " + assignee.String()
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(moderationCase).Update("assigned_to", assignee).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ModerationAction{
+			CaseID:       &moderationCase.ID,
+			ModeratorID:  &moderatorID,
+			Action:       models.ModerationAssign,
+			TargetType:   moderationCase.TargetType,
+			TargetID:     moderationCase.TargetID,
+			TargetUserID: moderationCase.TargetUserID,
+			Note:         note,
+		}).Error
+	})
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to assign case", err)
+	}
+
+	moderationCase.AssignedTo = assignee
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Case assignment updated", moderationCase)
+}
+
+// loadCase loads the case named by :case_id.
+func loadCase(c *fiber.Ctx, db *gorm.DB) (*models.ModerationCase, error) {
+	caseID, err := strconv.Atoi(c.Params("case_id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid case ID format")
+	}
+	var moderationCase models.ModerationCase
+	if err := db.First(&moderationCase, caseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Case not found")
+		}
+		return nil, err
+	}
+	return &moderationCase, nil
+}
+
+// targetSnapshot returns the reported content as moderators see it, including
+// hidden and deleted content. It is nil when the target no longer exists.
+func targetSnapshot(db *gorm.DB, targetType, targetID string) (map[string]interface{}, error) {
+	var query *gorm.DB
+	switch targetType {
+	case models.ReportTargetPost:
+		query = db.Table("posts").Select("id, user_id, content, media_url, post_type, community_id, hidden_at, deleted_at, created_at")
+	case models.ReportTargetComment:
+		query = db.Table("comments").Select("id, post_id, user_id, content, hidden_at, deleted_at, created_at")
+	case models.ReportTargetMessage:
+		query = db.Table("messages").Select("id, community_id, user_id, message, hidden_at, created_at")
+	case models.ReportTargetUser:
+		query = db.Table("users").Select("id, username, name, profile_pic_url, suspended_until, created_at")
+	default:
+		return nil, nil
+	}
+
+	rows := []map[string]interface{}{}
+	if err := query.Where("id = ?", targetID).Limit(1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
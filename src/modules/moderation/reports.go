@@ -0,0 +1,235 @@
+package moderation
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/feed"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxReportDetailsLength = 1000
+
+// ReportPost files a report against the post named by :post_id.
+func ReportPost(c *fiber.Ctx) error {
+	return fileReport(c, models.ReportTargetPost, c.Params("post_id"))
+}
+
+// ReportComment files a report against the comment named by :comment_id.
+func ReportComment(c *fiber.Ctx) error {
+	return fileReport(c, models.ReportTargetComment, c.Params("comment_id"))
+}
+
+// ReportMessage files a report against the community message named by :message_id.
+func ReportMessage(c *fiber.Ctx) error {
+	return fileReport(c, models.ReportTargetMessage, c.Params("message_id"))
+}
+
+// ReportUser files a report against the user named by :user_id.
+func ReportUser(c *fiber.Ctx) error {
+	return fileReport(c, models.ReportTargetUser, c.Params("user_id"))
+}
+
+// fileReport adds the caller's report to the open case of the target, opening
+// one if needed. Each user reports a target once per case; once the case
+// collects enough distinct reports the content is hidden until reviewed.
+func fileReport(c *fiber.Ctx, targetType, targetID string) error {
+	db := database.DB
+
+	reporterID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+
+	var req struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+	}
+	if !isValidReason(req.Reason) {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Reason must be one of: "+strings.Join(models.ReportReasons, ", "), nil)
+	}
+	details := strings.TrimSpace(req.Details)
+	if len([]rune(details)) > maxReportDetailsLength {
+		return helpers.HandleError(c, fiber.StatusBadRequest, fmt.Sprintf("Details may be at most %d characters", maxReportDetailsLength), nil)
+	}
+
+	targetID, targetUserID, err := resolveTarget(db, reporterID, targetType, targetID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+	if targetUserID == reporterID {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "You cannot report yourself or your own content", nil)
+	}
+
+	threshold := autoHideThreshold()
+	var report models.ContentReport
+	err = db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		caseID, err := openCase(tx, targetType, targetID, targetUserID, now)
+		if err != nil {
+			return err
+		}
+
+		report = models.ContentReport{CaseID: caseID, ReporterID: reporterID, Reason: req.Reason, Details: details}
+		result := tx.Raw(`
+			INSERT INTO content_reports (case_id, reporter_id, reason, details, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (case_id, reporter_id) DO NOTHING
+			RETURNING id, created_at
+		`, caseID, reporterID, req.Reason, details, now).Scan(&report)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fiber.NewError(fiber.StatusConflict, "You have already reported this")
+		}
+
+		var moderationCase models.ModerationCase
+		if err := tx.Raw("UPDATE moderation_cases SET report_count = report_count + 1 WHERE id = ? RETURNING *", caseID).
+			Scan(&moderationCase).Error; err != nil {
+			return err
+		}
+
+		if threshold > 0 && moderationCase.ReportCount >= threshold && !moderationCase.AutoHidden && targetType != models.ReportTargetUser {
+			return autoHide(tx, &moderationCase, threshold)
+		}
+		return nil
+	})
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Moderation request failed")
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Report submitted successfully", report)
+}
+
+// openCase returns the ID of the open case of the target, opening one if there is none.
+func openCase(tx *gorm.DB, targetType, targetID string, targetUserID uuid.UUID, now time.Time) (int, error) {
+	var caseID int
+	err := tx.Raw(`
+		INSERT INTO moderation_cases (target_type, target_id, target_user_id, status, report_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?)
+		ON CONFLICT (target_type, target_id) WHERE status = 'open' DO UPDATE SET updated_at = EXCLUDED.updated_at
+		RETURNING id
+	`, targetType, targetID, targetUserID, models.CaseStatusOpen, now, now).Scan(&caseID).Error
+	return caseID, err
+}
+
+// autoHide hides the case's content pending review and records it in the audit trail.
+func autoHide(tx *gorm.DB, moderationCase *models.ModerationCase, threshold int) error {
+	hidden, err := setHidden(tx, moderationCase.TargetType, moderationCase.TargetID, true)
+	if err != nil {
+		return err
+	}
+	if !hidden {
+		return nil // already hidden by a moderator
+	}
+	if err := tx.Model(moderationCase).Update("auto_hidden", true).Error; err != nil {
+		return err
+	}
+	log.Printf("Auto-hid %s %s after %d reports\n", moderationCase.TargetType, moderationCase.TargetID, moderationCase.ReportCount)
+	return tx.Create(&models.ModerationAction{
+		CaseID:       &moderationCase.ID,
+		Action:       models.ModerationHide,
+		TargetType:   moderationCase.TargetType,
+		TargetID:     moderationCase.TargetID,
+		TargetUserID: moderationCase.TargetUserID,
+		Note:         fmt.Sprintf("Automatically hidden after %d reports", threshold),
+	}).Error
+}
+
+// resolveTarget checks that the target exists and the reporter can see it, and
+// returns its canonical ID with the ID of the user responsible for it.
+func resolveTarget(db *gorm.DB, reporterID uuid.UUID, targetType, rawID string) (string, uuid.UUID, error) {
+	var authorIDs []uuid.UUID
+	var query *gorm.DB
+
+	switch targetType {
+	case models.ReportTargetPost:
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			return "", uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid post ID format")
+		}
+		rawID = id.String()
+		query = db.Table("posts").Select("posts.user_id").Where("posts.id = ?", id).Where(feed.VisiblePostsClause, reporterID)
+	case models.ReportTargetComment:
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			return "", uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid comment ID format")
+		}
+		rawID = id.String()
+		query = db.Table("comments").Select("comments.user_id").
+			Joins("JOIN posts ON posts.id = comments.post_id").
+			Where("comments.id = ? AND comments.deleted_at IS NULL AND comments.hidden_at IS NULL", id).
+			Where(feed.VisiblePostsClause, reporterID)
+	case models.ReportTargetMessage:
+		id, err := strconv.Atoi(rawID)
+		if err != nil {
+			return "", uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid message ID format")
+		}
+		rawID = strconv.Itoa(id)
+		query = db.Table("messages").Select("messages.user_id").
+			Where("messages.id = ? AND messages.hidden_at IS NULL", id).
+			Where("messages.community_id IN (SELECT community_id FROM community_members WHERE user_id = ?)", reporterID)
+	case models.ReportTargetUser:
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			return "", uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format")
+		}
+		rawID = id.String()
+		query = db.Table("users").Select("id").Where("id = ?", id)
+	default:
+		return "", uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Unknown report target type")
+	}
+
+	if err := query.Limit(1).Pluck("user_id", &authorIDs).Error; err != nil {
+		return "", uuid.Nil, err
+	}
+	if len(authorIDs) == 0 {
+		return "", uuid.Nil, fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("%s not found", strings.ToUpper(targetType[:1])+targetType[1:]))
+	}
+	return rawID, authorIDs[0], nil
+}
+
+// setHidden hides or unhides reported content, reporting whether anything changed.
+func setHidden(tx *gorm.DB, targetType, targetID string, hidden bool) (bool, error) {
+	table, ok := contentTables[targetType]
+	if !ok {
+		return false, fiber.NewError(fiber.StatusBadRequest, "Users cannot be hidden; suspend them instead")
+	}
+	query := tx.Table(table).Where("id = ?", targetID)
+	var value interface{}
+	if hidden {
+		query, value = query.Where("hidden_at IS NULL"), time.Now()
+	} else {
+		query = query.Where("hidden_at IS NOT NULL")
+	}
+	result := query.Update("hidden_at", value)
+	return result.RowsAffected > 0, result.Error
+}
+
+// contentTables maps hideable target types to their tables.
+var contentTables = map[string]string{
+	models.ReportTargetPost:    "posts",
+	models.ReportTargetComment: "comments",
+	models.ReportTargetMessage: "messages",
+}
+
+func isValidReason(reason string) bool {
+	for _, r := range models.ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
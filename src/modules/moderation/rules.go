@@ -0,0 +1,225 @@
+package moderation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// anyLanguage is the rule set applied to content in every language.
+const anyLanguage = "*"
+
+// Rule is one entry of a rule file: either a list of words, matched whole and
+// case-insensitively, or a regular expression. Label names the rule in flags.
+type Rule struct {
+	Action  string   `json:"action"`
+	Label   string   `json:"label"`
+	Words   []string `json:"words,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+}
+
+// RuleSet is the content of a rule file. Languages maps language codes, or
+// "*" for every language, to their rules. Content is matched against the "*"
+// rules and those of its language; content in Latin script is taken to be in
+// DefaultLanguage.
+type RuleSet struct {
+	DefaultLanguage string            `json:"default_language"`
+	Languages       map[string][]Rule `json:"languages"`
+}
+
+// defaultRules are used when MODERATION_RULES_FILE is not set. They only catch
+// obvious spam and mask common English profanity; deployments are expected to
+// ship their own lists.
+var defaultRules = RuleSet{
+	DefaultLanguage: "en",
+	Languages: map[string][]Rule{
+		anyLanguage: {
+			{Action: FilterFlag, Label: "spam", Pattern: `(?i)(https?://\S+\s+){4,}https?://\S+`},
+			{Action: FilterFlag, Label: "spam", Pattern: `(?i)\b(buy|cheap)\s+(followers|likes|subscribers)\b`},
+		},
+		"en": {
+			{Action: FilterMask, Label: "profanity", Words: []string{"fuck", "fucking", "shit", "bitch", "asshole", "bastard", "dickhead"}},
+			{Action: FilterFlag, Label: "scam", Pattern: `(?i)\b(free|double)\s+(crypto|bitcoin|btc|eth)\b`},
+			{Action: FilterBlock, Label: "self_harm", Pattern: `(?i)\b(go\s+)?kill\s+yourself\b`},
+		},
+	},
+}
+
+// compiledRule is a Rule ready for matching. Word rules need their matches to
+// stand alone, which RE2 cannot express outside ASCII, so it is checked by hand.
+type compiledRule struct {
+	action    string
+	label     string
+	re        *regexp.Regexp
+	wholeWord bool
+}
+
+// RuleEngine applies a RuleSet to text.
+type RuleEngine struct {
+	defaultLanguage string
+	languages       map[string][]compiledRule
+}
+
+// ruleMatch is where a rule matched, as byte offsets into the text.
+type ruleMatch struct {
+	action     string
+	label      string
+	start, end int
+}
+
+// NewRuleEngine compiles the rule set, rejecting unknown actions and invalid patterns.
+func NewRuleEngine(set RuleSet) (*RuleEngine, error) {
+	engine := &RuleEngine{
+		defaultLanguage: strings.ToLower(set.DefaultLanguage),
+		languages:       make(map[string][]compiledRule, len(set.Languages)),
+	}
+	if engine.defaultLanguage == "" {
+		engine.defaultLanguage = "en"
+	}
+
+	for language, rules := range set.Languages {
+		language = strings.ToLower(language)
+		for i, rule := range rules {
+			if filterSeverity(rule.Action) <= filterSeverity(FilterAllow) {
+				return nil, fmt.Errorf("rule %d for %q: action must be mask, flag or block", i, language)
+			}
+			compiled := compiledRule{action: rule.Action, label: rule.Label}
+			switch {
+			case len(rule.Words) > 0 && rule.Pattern == "":
+				words := make([]string, 0, len(rule.Words))
+				for _, word := range rule.Words {
+					if word = strings.TrimSpace(word); word != "" {
+						words = append(words, regexp.QuoteMeta(word))
+					}
+				}
+				if len(words) == 0 {
+					continue
+				}
+				compiled.re = regexp.MustCompile(`(?i)(?:` + strings.Join(words, "|") + `)`)
+				compiled.wholeWord = true
+			case rule.Pattern != "" && len(rule.Words) == 0:
+				re, err := regexp.Compile(rule.Pattern)
+				if err != nil {
+					return nil, fmt.Errorf("rule %d for %q: %w", i, language, err)
+				}
+				compiled.re = re
+			default:
+				return nil, fmt.Errorf("rule %d for %q: exactly one of words and pattern is required", i, language)
+			}
+			if compiled.label == "" {
+				compiled.label = compiled.action
+			}
+			engine.languages[language] = append(engine.languages[language], compiled)
+		}
+	}
+	return engine, nil
+}
+
+// LoadRuleEngine compiles the rule file at path, or the default rules when path is empty.
+func LoadRuleEngine(path string) (*RuleEngine, error) {
+	if path == "" {
+		return NewRuleEngine(defaultRules)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set RuleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid rule file %s: %w", path, err)
+	}
+	return NewRuleEngine(set)
+}
+
+// match returns every rule match in text.
+func (e *RuleEngine) match(text string) []ruleMatch {
+	var matches []ruleMatch
+	apply := func(rules []compiledRule) {
+		for _, rule := range rules {
+			for _, loc := range rule.re.FindAllStringIndex(text, -1) {
+				if rule.wholeWord && !standsAlone(text, loc[0], loc[1]) {
+					continue
+				}
+				matches = append(matches, ruleMatch{action: rule.action, label: rule.label, start: loc[0], end: loc[1]})
+			}
+		}
+	}
+	apply(e.languages[anyLanguage])
+	if language := e.detectLanguage(text); language != anyLanguage {
+		apply(e.languages[language])
+	}
+	return matches
+}
+
+// standsAlone reports whether text[start:end] is not part of a longer word.
+func standsAlone(text string, start, end int) bool {
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:start]); isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		if r, _ := utf8.DecodeRuneInString(text[end:]); isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// scriptLanguages maps writing systems to the language whose rules apply to them.
+var scriptLanguages = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Devanagari, "hi"},
+	{unicode.Bengali, "bn"},
+	{unicode.Tamil, "ta"},
+	{unicode.Telugu, "te"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Thai, "th"},
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+}
+
+// detectLanguage guesses the language of text from the script most of its
+// letters are written in. Latin script maps to the default language.
+func (e *RuleEngine) detectLanguage(text string) string {
+	counts := make(map[string]int)
+	latin := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if unicode.Is(unicode.Latin, r) {
+			latin++
+			continue
+		}
+		for _, candidate := range scriptLanguages {
+			if unicode.Is(candidate.script, r) {
+				counts[candidate.language]++
+				break
+			}
+		}
+	}
+
+	language, best := e.defaultLanguage, latin
+	for _, candidate := range scriptLanguages {
+		if count := counts[candidate.language]; count > best {
+			language, best = candidate.language, count
+		}
+	}
+	return language
+}
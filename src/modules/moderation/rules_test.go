@@ -0,0 +1,104 @@
+package moderation
+
+import "testing"
+
+func TestNewRuleEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr bool
+	}{
+		{"word rule", []Rule{{Action: FilterMask, Words: []string{"darn"}}}, false},
+		{"pattern rule", []Rule{{Action: FilterFlag, Pattern: `(?i)spam+`}}, false},
+		{"blank words are skipped", []Rule{{Action: FilterMask, Words: []string{" ", ""}}}, false},
+		{"allow is not a rule action", []Rule{{Action: FilterAllow, Words: []string{"darn"}}}, true},
+		{"unknown action", []Rule{{Action: "ban", Words: []string{"darn"}}}, true},
+		{"words and pattern", []Rule{{Action: FilterMask, Words: []string{"darn"}, Pattern: "darn"}}, true},
+		{"neither words nor pattern", []Rule{{Action: FilterMask}}, true},
+		{"invalid pattern", []Rule{{Action: FilterFlag, Pattern: `(unclosed`}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRuleEngine(RuleSet{Languages: map[string][]Rule{"en": tt.rules}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRuleEngine error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleEngineMatch(t *testing.T) {
+	engine, err := NewRuleEngine(RuleSet{
+		DefaultLanguage: "EN",
+		Languages: map[string][]Rule{
+			anyLanguage: {{Action: FilterFlag, Pattern: `(?i)\bscam\b`}},
+			"en":        {{Action: FilterMask, Label: "profanity", Words: []string{"darn", "heck"}}},
+			"ru":        {{Action: FilterBlock, Label: "threat", Words: []string{"угроза"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleEngine: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []ruleMatch
+	}{
+		{"no match", "all good here", nil},
+		{"whole word", "darn it", []ruleMatch{{FilterMask, "profanity", 0, 4}}},
+		{"every occurrence", "heck, DARN", []ruleMatch{{FilterMask, "profanity", 0, 4}, {FilterMask, "profanity", 6, 10}}},
+		{"part of a word", "darned hecking", nil},
+		{"underscore joins words", "darn_it", nil},
+		{"label defaults to the action", "a scam", []ruleMatch{{FilterFlag, FilterFlag, 2, 6}}},
+		{"rules for every language", "это scam", []ruleMatch{{FilterFlag, FilterFlag, 7, 11}}},
+		{"rules for the detected language", "это угроза", []ruleMatch{{FilterBlock, "threat", 7, 19}}},
+		{"non-Latin word boundaries", "этоугроза", nil},
+		{"other languages' rules do not apply", "darn это очень", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := engine.match(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("match(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("match(%q)[%d] = %v, want %v", tt.text, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	engine := &RuleEngine{defaultLanguage: "es"}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"Latin script", "hola amigos", "es"},
+		{"no letters", "123 !?", "es"},
+		{"Cyrillic", "привет мир", "ru"},
+		{"Devanagari", "नमस्ते दुनिया", "hi"},
+		{"Arabic", "مرحبا بالعالم", "ar"},
+		{"Hiragana", "こんにちは", "ja"},
+		{"Katakana", "コンピュータ", "ja"},
+		{"Han", "你好世界", "zh"},
+		{"Hangul", "안녕하세요", "ko"},
+		{"mostly Latin", "hello мир", "es"},
+		{"mostly Cyrillic", "ok привет", "ru"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := engine.detectLanguage(tt.text); got != tt.want {
+				t.Errorf("detectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
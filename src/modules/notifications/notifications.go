@@ -1,16 +1,19 @@
 package notifications
 
 import (
+	"Backend/src/core/models"
 	"log"
 	"sync"
-	
+	"time"
 
 	// "github.com/gofiber/fiber/v
 	"github.com/gofiber/websocket/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-// Store WebSocket connections
-var notificationClients = make(map[*websocket.Conn]bool)
+// Store WebSocket connections by the ID of the user they were opened by
+var notificationClients = make(map[string]map[*websocket.Conn]bool)
 var mu sync.Mutex
 var notificationBroadcast = make(chan Notification)
 
@@ -21,17 +24,27 @@ type Notification struct {
 	Message string `json:"message"`
 }
 
-// Fiber WebSocket Handler
+// Fiber WebSocket Handler. The route authenticates the upgrade with
+// middleware.ProtectedSocket, so user_id is the verified caller.
 func NotificationWebSocketHandler(c *websocket.Conn) {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		c.Close()
+		return
+	}
+
 	mu.Lock()
-	notificationClients[c] = true
+	if notificationClients[userID] == nil {
+		notificationClients[userID] = make(map[*websocket.Conn]bool)
+	}
+	notificationClients[userID][c] = true
 	mu.Unlock()
 
 	log.Println("New WebSocket client connected for notifications")
 
 	defer func() {
 		mu.Lock()
-		delete(notificationClients, c)
+		removeClient(userID, c)
 		mu.Unlock()
 		c.Close()
 	}()
@@ -46,23 +59,45 @@ func NotificationWebSocketHandler(c *websocket.Conn) {
 	}
 }
 
-// Broadcast Notifications
+// BroadcastNotifications delivers each notification to the connections of
+// the user it is addressed to.
 func BroadcastNotifications() {
 	for {
 		notification := <-notificationBroadcast
 		mu.Lock()
-		for client := range notificationClients {
+		for client := range notificationClients[notification.UserID] {
 			err := client.WriteJSON(notification)
 			if err != nil {
 				log.Println("Error sending notification:", err)
 				client.Close()
-				delete(notificationClients, client)
+				removeClient(notification.UserID, client)
 			}
 		}
 		mu.Unlock()
 	}
 }
 
+// removeClient forgets a connection. The caller holds mu.
+func removeClient(userID string, client *websocket.Conn) {
+	delete(notificationClients[userID], client)
+	if len(notificationClients[userID]) == 0 {
+		delete(notificationClients, userID)
+	}
+}
+
+// connectedUsers returns which of the users have a notification socket open.
+func connectedUsers(userIDs []uuid.UUID) []string {
+	mu.Lock()
+	defer mu.Unlock()
+	var connected []string
+	for _, userID := range userIDs {
+		if id := userID.String(); len(notificationClients[id]) > 0 {
+			connected = append(connected, id)
+		}
+	}
+	return connected
+}
+
 // Function to trigger notifications
 func SendNotification(userID, title, message string) {
 	notification := Notification{
@@ -73,3 +108,33 @@ func SendNotification(userID, title, message string) {
 	notificationBroadcast <- notification
 }
 
+// NotifyUsers stores a notification for each user and pushes it over the notification socket.
+func NotifyUsers(db *gorm.DB, userIDs []uuid.UUID, category, title, message string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	records := make([]models.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		records[i] = models.Notification{
+			UserID:    userID,
+			Message:   message,
+			Category:  category,
+			CreatedAt: now,
+		}
+	}
+	if err := db.CreateInBatches(&records, 500).Error; err != nil {
+		return err
+	}
+
+	// Only connected users get a push; the rest read the stored notification
+	if connected := connectedUsers(userIDs); len(connected) > 0 {
+		go func() {
+			for _, userID := range connected {
+				SendNotification(userID, title, message)
+			}
+		}()
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package posts
+
+import (
+	"Backend/src/core/models"
+	"Backend/src/modules/media"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+const maxAttachments = 10
+
+// parseAttachments reads and processes every "media" file of the multipart form,
+// in the order they were sent.
+func parseAttachments(c *fiber.Ctx) ([]*media.Processed, error) {
+	form, err := c.MultipartForm()
+	if errors.Is(err, fasthttp.ErrNoMultipartForm) {
+		return nil, nil // not a multipart request, so no files
+	}
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid multipart form")
+	}
+
+	files := form.File["media"]
+	if len(files) > maxAttachments {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("A post can have at most %d attachments", maxAttachments))
+	}
+
+	processed := make([]*media.Processed, 0, len(files))
+	for _, file := range files {
+		if file.Size > int64(media.MaxSize()) {
+			return nil, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("%s is too large", file.Filename))
+		}
+		content, err := file.Open()
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to open media file")
+		}
+		data, err := io.ReadAll(content)
+		content.Close()
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to read media file")
+		}
+
+		p, err := media.Process(data)
+		switch {
+		case errors.Is(err, media.ErrUnsupportedType):
+			return nil, fiber.NewError(fiber.StatusUnsupportedMediaType, fmt.Sprintf("%s: %v", file.Filename, err))
+		case errors.Is(err, media.ErrTooLarge):
+			return nil, fiber.NewError(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("%s: %v", file.Filename, err))
+		case err != nil:
+			log.Printf("Error processing media %s: %v\n", file.Filename, err)
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to process media file")
+		}
+		processed = append(processed, p)
+	}
+	return processed, nil
+}
+
+// uploadAttachments stores the processed files and their variants. On failure
+// the objects uploaded so far are removed again.
+func uploadAttachments(processed []*media.Processed) ([]models.PostAttachment, error) {
+	attachments := make([]models.PostAttachment, 0, len(processed))
+	var uploaded []string
+
+	upload := func(data []byte, ext string) (string, error) {
+		url, err := uploadToSupabase(uuid.New().String()+ext, bytes.NewReader(data))
+		if err == nil {
+			uploaded = append(uploaded, url)
+		}
+		return url, err
+	}
+
+	for i, p := range processed {
+		url, err := upload(p.Data, p.Ext)
+		if err != nil {
+			go deleteAttachmentObjects(uploaded)
+			return nil, err
+		}
+		attachment := models.PostAttachment{
+			Position:        i,
+			URL:             url,
+			MediaType:       p.MediaType,
+			ContentType:     p.ContentType,
+			SizeBytes:       int64(len(p.Data)),
+			DurationSeconds: p.Duration,
+		}
+		if p.MediaType == media.TypeImage {
+			width, height := p.Width, p.Height
+			attachment.Width, attachment.Height = &width, &height
+		}
+
+		for _, variant := range p.Variants {
+			variantURL, err := upload(variant.Data, variant.Ext)
+			if err != nil {
+				go deleteAttachmentObjects(uploaded)
+				return nil, err
+			}
+			switch variant.Name {
+			case "thumbnail":
+				attachment.ThumbnailURL = variantURL
+			case "medium":
+				attachment.MediumURL = variantURL
+			}
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// attachmentObjects lists the storage URLs of a post's attachments and their variants.
+func attachmentObjects(db *gorm.DB, postID uuid.UUID) ([]string, error) {
+	var attachments []models.PostAttachment
+	if err := db.Where("post_id = ?", postID).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachmentURLs(attachments), nil
+}
+
+// attachmentURLs lists the stored objects behind the attachments, variants included.
+func attachmentURLs(attachments []models.PostAttachment) []string {
+	var urls []string
+	for _, attachment := range attachments {
+		for _, url := range []string{attachment.URL, attachment.ThumbnailURL, attachment.MediumURL} {
+			if url != "" {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+func deleteAttachmentObjects(urls []string) {
+	for _, url := range urls {
+		if err := deleteFromSupabase(url); err != nil {
+			log.Printf("Failed to delete media object %s: %v\n", url, err)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package posts
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/moderation"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CommentView is a comment with its author and the number of live replies.
+type CommentView struct {
+	ID            uuid.UUID  `json:"id"`
+	PostID        uuid.UUID  `json:"post_id"`
+	ParentID      *uuid.UUID `json:"parent_id,omitempty"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Username      string     `json:"username"`
+	ProfilePicURL string     `json:"profile_pic_url"`
+	Content       string     `json:"content"`
+	ReplyCount    int        `json:"reply_count"`
+	CreatedAt     time.Time  `json:"created_at"`
+	EditedAt      *time.Time `json:"edited_at,omitempty"`
+}
+
+// GetComments lists a post's top-level comments, oldest first, or the replies
+// to a single comment when ?parent_id is given.
+func GetComments(c *fiber.Ctx) error {
+	db := database.DB
+
+	postID, err := uuid.Parse(c.Params("post_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid post ID format", err)
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	postExists, err := postVisible(db, userID, postID.String())
+	if err != nil || !postExists {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Post not found", err)
+	}
+
+	limit, offset := helpers.ParsePagination(c, 20, 100)
+
+	var parentID *uuid.UUID
+	if value := c.Query("parent_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid parent comment ID format", err)
+		}
+		parentID = &parsed
+	}
+
+	comments, total, err := fetchComments(db, postID, parentID, limit, offset)
+	if err != nil {
+		log.Printf("Error fetching comments for post %s: %v\n", postID, err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch comments", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Comments fetched successfully", fiber.Map{
+		"post_id":   postID,
+		"parent_id": parentID,
+		"total":     total,
+		"limit":     limit,
+		"offset":    offset,
+		"comments":  comments,
+	})
+}
+
+// fetchComments returns one page of live comments under parentID (top level when
+// nil) together with the total number of such comments.
+func fetchComments(db *gorm.DB, postID uuid.UUID, parentID *uuid.UUID, limit, offset int) ([]CommentView, int64, error) {
+	query := db.Table("comments").
+		Where("comments.post_id = ? AND comments.deleted_at IS NULL AND comments.hidden_at IS NULL", postID)
+	if parentID != nil {
+		query = query.Where("comments.parent_id = ?", *parentID)
+	} else {
+		query = query.Where("comments.parent_id IS NULL")
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	comments := []CommentView{}
+	err := query.
+		Select(`comments.id, comments.post_id, comments.parent_id, comments.user_id,
+			users.username, users.profile_pic_url, comments.content, comments.created_at, comments.edited_at,
+			(SELECT COUNT(*) FROM comments replies WHERE replies.parent_id = comments.id AND replies.deleted_at IS NULL AND replies.hidden_at IS NULL) AS reply_count`).
+		Joins("JOIN users ON users.id = comments.user_id").
+		Order("comments.created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&comments).Error
+	return comments, total, err
+}
+
+// UpdateComment lets the author change a comment's content.
+func UpdateComment(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, comment, err := loadComment(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	if comment.UserID != userID {
+		return helpers.HandleError(c, fiber.StatusForbidden, "You can only edit your own comments", nil)
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Comment content cannot be empty", nil)
+	}
+	verdict, err := screenContent(content)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	content = verdict.Text
+
+	now := time.Now()
+	if err := db.Model(&models.Comment{}).Where("id = ?", comment.ID).
+		Updates(map[string]interface{}{"content": content, "edited_at": now}).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to update comment", err)
+	}
+	if verdict.Flagged() {
+		go moderation.FlagContent(db, models.ReportTargetComment, comment.ID.String(), userID, verdict.Labels)
+	}
+
+	if err := syncHashtags(db, comment.PostID); err != nil {
+		log.Printf("Error saving hashtags of comment %s: %v\n", comment.ID, err)
+	}
+	go notifyMentions(db, userID, newMentions(comment.Content, content), "comment", comment.PostID)
+
+	comment.Content, comment.EditedAt = content, &now
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Comment updated successfully", comment)
+}
+
+// DeleteComment soft-deletes a comment and every reply under it. Both its
+// author and the owner of the post may remove it.
+func DeleteComment(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, comment, err := loadComment(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	if comment.UserID != userID {
+		var postOwnerID uuid.UUID
+		if err := db.Table("posts").Where("id = ?", comment.PostID).Select("user_id").Scan(&postOwnerID).Error; err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch post", err)
+		}
+		if postOwnerID != userID {
+			return helpers.HandleError(c, fiber.StatusForbidden, "You can only delete your own comments or comments on your posts", nil)
+		}
+	}
+
+	if err := deleteCommentTree(db, comment.ID, time.Now()); err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to delete comment", err)
+	}
+	if err := syncHashtags(db, comment.PostID); err != nil {
+		log.Printf("Error removing hashtags of comment %s: %v\n", comment.ID, err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Comment deleted successfully", nil)
+}
+
+// deleteCommentTree soft-deletes a comment together with all of its replies in
+// a single statement, so listings, reply counts and comments_count never see a
+// live reply under a deleted parent.
+func deleteCommentTree(db *gorm.DB, commentID uuid.UUID, now time.Time) error {
+	return db.Exec(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM comments WHERE id = ?
+			UNION ALL
+			SELECT replies.id FROM comments replies JOIN subtree ON replies.parent_id = subtree.id
+		)
+		UPDATE comments SET deleted_at = ?
+		WHERE id IN (SELECT id FROM subtree) AND deleted_at IS NULL`, commentID, now).Error
+}
+
+// loadComment returns the caller and the live comment named by :comment_id on a
+// live post.
+func loadComment(c *fiber.Ctx, db *gorm.DB) (uuid.UUID, *models.Comment, error) {
+	userId, ok := c.Locals("user_id").(string)
+	if !ok || userId == "" {
+		return uuid.Nil, nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or missing user_id")
+	}
+	userID, err := uuid.Parse(userId)
+	if err != nil {
+		return uuid.Nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format")
+	}
+
+	commentID, err := uuid.Parse(c.Params("comment_id"))
+	if err != nil {
+		return uuid.Nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid comment ID format")
+	}
+
+	var comment models.Comment
+	err = db.Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Where("comments.id = ? AND comments.deleted_at IS NULL", commentID).
+		First(&comment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return uuid.Nil, nil, fiber.NewError(fiber.StatusNotFound, "Comment not found")
+	}
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	return userID, &comment, nil
+}
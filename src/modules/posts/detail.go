@@ -0,0 +1,99 @@
+package posts
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/analytics"
+	"Backend/src/modules/feed"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Size of the comment page embedded in the post detail
+const (
+	detailCommentsLimit    = 20
+	maxDetailCommentsLimit = 100
+)
+
+// GetPost returns a single visible post as the feed renders it, with the
+// caller's reaction and bookmark and the first page of top-level comments.
+// The number of queries does not depend on the post's engagement.
+func GetPost(c *fiber.Ctx) error {
+	db := database.DB
+
+	userId, ok := c.Locals("user_id").(string)
+	if !ok || userId == "" {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", nil)
+	}
+	userID, err := uuid.Parse(userId)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid user ID format", err)
+	}
+	postID, err := uuid.Parse(c.Params("post_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid post ID format", err)
+	}
+
+	commentsLimit := c.QueryInt("comments_limit", detailCommentsLimit)
+	if commentsLimit <= 0 {
+		commentsLimit = detailCommentsLimit
+	}
+	if commentsLimit > maxDetailCommentsLimit {
+		commentsLimit = maxDetailCommentsLimit
+	}
+
+	post, err := fetchPostDetail(userID, postID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	var reactions []string
+	if err := db.Table("likes").Where("post_id = ? AND user_id = ?", postID, userID).
+		Limit(1).Pluck("reaction_type", &reactions).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch your reaction", err)
+	}
+	var reaction *string
+	if len(reactions) > 0 {
+		reaction = &reactions[0]
+	}
+
+	analytics.RecordView(userID, postID)
+
+	comments, total, err := fetchComments(db, postID, nil, commentsLimit, 0)
+	if err != nil {
+		log.Printf("Error fetching comments for post %s: %v\n", postID, err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch comments", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Post fetched successfully", fiber.Map{
+		"post":     post,
+		"liked":    reaction != nil,
+		"reaction": reaction,
+		"comments": fiber.Map{
+			"total":    total,
+			"limit":    commentsLimit,
+			"offset":   0,
+			"comments": comments,
+		},
+	})
+}
+
+// fetchPostDetail loads the post with the engagement and viewer state the feed
+// shows.
+func fetchPostDetail(viewerID, postID uuid.UUID) (*feed.FeedPost, error) {
+	var posts []models.Post
+	if err := feed.PostQuery(database.DB, viewerID).Where("posts.id = ?", postID).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Post not found")
+	}
+	feedPosts := feed.BuildFeedPosts(posts)
+	if err := feed.ApplyViewerState(viewerID, feedPosts); err != nil {
+		return nil, err
+	}
+	return &feedPosts[0], nil
+}
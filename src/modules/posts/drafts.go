@@ -0,0 +1,440 @@
+package posts
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/notifications"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	schedulerInterval  = 30 * time.Second
+	schedulerBatchSize = 100
+)
+
+// CreateDraft saves an unpublished post. It takes the same form as CreatePost,
+// all fields optional, plus an optional RFC 3339 scheduled_at that schedules
+// the draft for publication.
+func CreateDraft(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	draft := models.PostDraft{UserID: userID}
+	if err := applyDraftForm(c, db, &draft); err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	if err := db.Create(&draft).Error; err != nil {
+		log.Printf("Error creating draft: %v\n", err)
+		go deleteAttachmentObjects(attachmentURLs(draft.Attachments))
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to save draft", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Draft saved successfully", draft)
+}
+
+// GetDrafts lists the caller's unscheduled drafts, most recently edited first.
+func GetDrafts(c *fiber.Ctx) error {
+	return listDrafts(c, models.DraftStatusDraft, "updated_at DESC")
+}
+
+// GetScheduledPosts lists the caller's scheduled posts in publication order.
+func GetScheduledPosts(c *fiber.Ctx) error {
+	return listDrafts(c, models.DraftStatusScheduled, "scheduled_at ASC")
+}
+
+func listDrafts(c *fiber.Ctx, status, order string) error {
+	db := database.DB
+
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	limit, offset := helpers.ParsePagination(c, 20, 100)
+
+	query := db.Model(&models.PostDraft{}).Where("user_id = ? AND status = ?", userID, status)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to count drafts", err)
+	}
+
+	drafts := []models.PostDraft{}
+	if err := query.Order(order).Order("id").Limit(limit).Offset(offset).Find(&drafts).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch drafts", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Drafts fetched successfully", fiber.Map{
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+		"drafts": drafts,
+	})
+}
+
+// GetDraft returns one of the caller's drafts, including published and failed ones.
+func GetDraft(c *fiber.Ctx) error {
+	db := database.DB
+
+	draft, err := loadOwnDraft(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Draft fetched successfully", draft)
+}
+
+// UpdateDraft replaces the fields of an unpublished draft with the submitted
+// form. Media files, when sent, replace the draft's attachments; remove_media
+// clears them. Sending scheduled_at schedules the draft, omitting it keeps the
+// draft unscheduled.
+func UpdateDraft(c *fiber.Ctx) error {
+	db := database.DB
+
+	draft, err := loadOwnDraft(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	if draft.Status == models.DraftStatusPublished {
+		return helpers.HandleError(c, fiber.StatusConflict, "This draft has already been published", nil)
+	}
+
+	previous := draft.Attachments
+	if err := applyDraftForm(c, db, draft); err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	if c.FormValue("remove_media") == "true" && sameAttachments(previous, draft.Attachments) {
+		draft.Attachments = nil
+	}
+	draft.Error = ""
+
+	// The status guard keeps the scheduler from publishing a half-edited draft
+	result := db.Model(draft).Where("status <> ?", models.DraftStatusPublished).
+		Select("content", "post_type", "community_id", "attachments", "poll", "status", "scheduled_at", "error", "updated_at").
+		Updates(draft)
+	if result.Error != nil || result.RowsAffected == 0 {
+		if !sameAttachments(previous, draft.Attachments) {
+			go deleteAttachmentObjects(attachmentURLs(draft.Attachments))
+		}
+		if result.Error != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to update draft", result.Error)
+		}
+		return helpers.HandleError(c, fiber.StatusConflict, "This draft has already been published", nil)
+	}
+
+	if len(previous) > 0 && !sameAttachments(previous, draft.Attachments) {
+		go deleteAttachmentObjects(attachmentURLs(previous))
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Draft updated successfully", draft)
+}
+
+// DeleteDraft removes an unpublished draft and its uploaded media.
+func DeleteDraft(c *fiber.Ctx) error {
+	db := database.DB
+
+	draft, err := loadOwnDraft(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	result := db.Where("id = ? AND status <> ?", draft.ID, models.DraftStatusPublished).Delete(&models.PostDraft{})
+	if result.Error != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to delete draft", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return helpers.HandleError(c, fiber.StatusConflict, "Published drafts cannot be deleted; delete the post instead", nil)
+	}
+
+	go deleteAttachmentObjects(attachmentURLs(draft.Attachments))
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Draft deleted successfully", nil)
+}
+
+// CancelScheduledPost unschedules a post, keeping it as a draft.
+func CancelScheduledPost(c *fiber.Ctx) error {
+	db := database.DB
+
+	draft, err := loadOwnDraft(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	result := db.Model(draft).Where("status = ?", models.DraftStatusScheduled).
+		Updates(map[string]interface{}{"status": models.DraftStatusDraft, "scheduled_at": nil, "updated_at": time.Now()})
+	if result.Error != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to cancel scheduled post", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return helpers.HandleError(c, fiber.StatusConflict, "This post is not scheduled", nil)
+	}
+
+	draft.Status, draft.ScheduledAt = models.DraftStatusDraft, nil
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Scheduled post cancelled successfully", draft)
+}
+
+// PublishDraft publishes a draft or scheduled post right away.
+func PublishDraft(c *fiber.Ctx) error {
+	db := database.DB
+
+	draft, err := loadOwnDraft(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	post, err := publishDraft(db, draft)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Draft published successfully", post)
+}
+
+// PublishScheduledPosts runs forever, publishing scheduled posts once their
+// time has come.
+func PublishScheduledPosts() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := publishDuePosts(database.DB); err != nil {
+			log.Printf("Error publishing scheduled posts: %v\n", err)
+		}
+	}
+}
+
+func publishDuePosts(db *gorm.DB) error {
+	var due []models.PostDraft
+	if err := db.Where("status = ? AND scheduled_at <= ?", models.DraftStatusScheduled, time.Now()).
+		Order("scheduled_at").Limit(schedulerBatchSize).Find(&due).Error; err != nil {
+		return err
+	}
+
+	for i := range due {
+		draft := &due[i]
+		_, err := publishDraft(db, draft)
+		var message string
+		switch {
+		case errors.Is(err, errDraftClaimed):
+			continue // edited, cancelled or published by another instance meanwhile
+		case err != nil:
+			log.Printf("Error publishing scheduled post %s: %v\n", draft.ID, err)
+			message = fmt.Sprintf("Your post scheduled for %s could not be published: %s", draft.ScheduledAt.Format(time.RFC822), draft.Error)
+		default:
+			message = fmt.Sprintf("Your post scheduled for %s is now live", draft.ScheduledAt.Format(time.RFC822))
+		}
+		if err := notifications.NotifyUsers(db, []uuid.UUID{draft.UserID}, "scheduled_post", "Scheduled post", message); err != nil {
+			log.Printf("Error notifying author of scheduled post %s: %v\n", draft.ID, err)
+		}
+	}
+	return nil
+}
+
+// errDraftClaimed is returned when the draft changed state before it could be published.
+var errDraftClaimed = fiber.NewError(fiber.StatusConflict, "This draft was changed or already published; reload it and try again")
+
+// publishDraft turns the draft into a post. The draft is claimed first, so it
+// is published at most once; when publication fails a scheduled draft is
+// marked failed with the reason, and an unscheduled one is left untouched.
+func publishDraft(db *gorm.DB, draft *models.PostDraft) (*models.Post, error) {
+	previousStatus := draft.Status
+	claim := db.Model(&models.PostDraft{}).
+		Where("id = ? AND status = ? AND updated_at = ?", draft.ID, previousStatus, draft.UpdatedAt).
+		Update("status", models.DraftStatusPublished)
+	if claim.Error != nil {
+		return nil, claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		return nil, errDraftClaimed
+	}
+
+	post, err := createPostFromDraft(db, draft)
+	if err != nil {
+		status := previousStatus
+		if previousStatus == models.DraftStatusScheduled {
+			status = models.DraftStatusFailed
+		}
+		draft.Status, draft.Error = status, failureReason(err)
+		if updateErr := db.Model(&models.PostDraft{}).Where("id = ?", draft.ID).
+			Updates(map[string]interface{}{"status": status, "error": draft.Error, "updated_at": time.Now()}).Error; updateErr != nil {
+			log.Printf("Error recording failure of draft %s: %v\n", draft.ID, updateErr)
+		}
+		return nil, err
+	}
+
+	draft.Status, draft.PostID = models.DraftStatusPublished, &post.ID
+	if err := db.Model(&models.PostDraft{}).Where("id = ?", draft.ID).
+		Updates(map[string]interface{}{"post_id": post.ID, "error": "", "updated_at": time.Now()}).Error; err != nil {
+		log.Printf("Error linking draft %s to post %s: %v\n", draft.ID, post.ID, err)
+	}
+	return post, nil
+}
+
+// createPostFromDraft re-validates the draft as it stands now and publishes it.
+func createPostFromDraft(db *gorm.DB, draft *models.PostDraft) (*models.Post, error) {
+	if strings.TrimSpace(draft.Content) == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Post content cannot be empty")
+	}
+	community, err := resolvePostCommunity(db, draft.UserID, draft.CommunityID, draft.PostType)
+	if err != nil {
+		return nil, err
+	}
+
+	var poll *models.Poll
+	var options []models.PollOption
+	if draft.PostType == models.PostTypePoll {
+		if draft.Poll == nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Poll posts need poll options")
+		}
+		if draft.Poll.ClosesAt != nil && !draft.Poll.ClosesAt.After(time.Now()) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "The poll's closing time has passed")
+		}
+		poll = &models.Poll{MultipleChoice: draft.Poll.MultipleChoice, ClosesAt: draft.Poll.ClosesAt}
+		for i, text := range draft.Poll.Options {
+			options = append(options, models.PollOption{Position: i, Text: text})
+		}
+	}
+
+	visibility, err := parseVisibility(draft.Visibility, draft.CommunityID)
+	if err != nil {
+		return nil, err
+	}
+	post := models.Post{
+		UserID:     draft.UserID,
+		Content:    draft.Content,
+		PostType:   draft.PostType,
+		Visibility: visibility,
+	}
+	attachments := append([]models.PostAttachment(nil), draft.Attachments...)
+	if err := publishPost(db, &post, community, attachments, poll, options); err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// applyDraftForm validates the submitted form and copies it onto the draft,
+// uploading any media files.
+func applyDraftForm(c *fiber.Ctx, db *gorm.DB, draft *models.PostDraft) error {
+	draft.Content = strings.TrimSpace(c.FormValue("content"))
+
+	draft.PostType = c.FormValue("type", models.PostTypePost)
+	if draft.PostType != models.PostTypePost && draft.PostType != models.PostTypeAnnouncement && draft.PostType != models.PostTypePoll {
+		return fiber.NewError(fiber.StatusBadRequest, "Post type must be post, announcement or poll")
+	}
+
+	draft.Poll = nil
+	if draft.PostType == models.PostTypePoll {
+		poll, options, err := parsePoll(c)
+		if err != nil {
+			return err
+		}
+		draft.Poll = &models.DraftPoll{MultipleChoice: poll.MultipleChoice, ClosesAt: poll.ClosesAt}
+		for _, option := range options {
+			draft.Poll.Options = append(draft.Poll.Options, option.Text)
+		}
+	}
+
+	draft.CommunityID = nil
+	if communityIDStr := c.FormValue("community_id"); communityIDStr != "" {
+		communityID, err := strconv.Atoi(communityIDStr)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid community ID format")
+		}
+		draft.CommunityID = &communityID
+	}
+	if _, err := resolvePostCommunity(db, draft.UserID, draft.CommunityID, draft.PostType); err != nil {
+		return err
+	}
+	visibility, err := parseVisibility(c.FormValue("visibility"), draft.CommunityID)
+	if err != nil {
+		return err
+	}
+	draft.Visibility = visibility
+
+	draft.Status, draft.ScheduledAt = models.DraftStatusDraft, nil
+	if scheduledAt := c.FormValue("scheduled_at"); scheduledAt != "" {
+		t, err := time.Parse(time.RFC3339, scheduledAt)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "scheduled_at must be an RFC 3339 timestamp")
+		}
+		if !t.After(time.Now()) {
+			return fiber.NewError(fiber.StatusBadRequest, "scheduled_at must be in the future")
+		}
+		if draft.Content == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "Post content cannot be empty")
+		}
+		if draft.Poll != nil && draft.Poll.ClosesAt != nil && !draft.Poll.ClosesAt.After(t) {
+			return fiber.NewError(fiber.StatusBadRequest, "poll_closes_at must be after scheduled_at")
+		}
+		draft.Status, draft.ScheduledAt = models.DraftStatusScheduled, &t
+	}
+
+	processed, err := parseAttachments(c)
+	if err != nil {
+		return err
+	}
+	if len(processed) > 0 {
+		attachments, err := uploadAttachments(processed)
+		if err != nil {
+			log.Printf("Media upload error: %v\n", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Failed to upload media")
+		}
+		draft.Attachments = attachments
+	}
+	return nil
+}
+
+// loadOwnDraft loads the draft named by :draft_id and checks the caller wrote
+// it.
+func loadOwnDraft(c *fiber.Ctx, db *gorm.DB) (*models.PostDraft, error) {
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return nil, err
+	}
+	draftID, err := uuid.Parse(c.Params("draft_id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid draft ID format")
+	}
+
+	var draft models.PostDraft
+	if err := db.Where("id = ? AND user_id = ?", draftID, userID).First(&draft).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Draft not found")
+		}
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// failureReason is the message stored on a draft that could not be published.
+func failureReason(err error) string {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Message
+	}
+	return "Failed to create post"
+}
+
+func sameAttachments(a, b []models.PostAttachment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].URL != b[i].URL {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,298 @@
+package posts
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/linkpreview"
+	"Backend/src/modules/moderation"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UpdatePost replaces the content of the caller's post, keeping the previous
+// version in post_edits. Predicted tags are refreshed in the background.
+func UpdatePost(c *fiber.Ctx) error {
+	db := database.DB
+
+	post, err := loadOwnPost(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	if post.PostType == models.PostTypeRepost {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Reposts cannot be edited", nil)
+	}
+
+	var req struct {
+		Content string `json:"content" form:"content"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Post content cannot be empty", nil)
+	}
+	verdict, err := screenContent(content)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	content = verdict.Text
+	if content == post.Content {
+		return helpers.HandleSuccess(c, fiber.StatusOK, "Post unchanged", post)
+	}
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.PostEdit{PostID: post.ID, Content: post.Content, EditedAt: now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("posts").Where("id = ?", post.ID).
+			Updates(map[string]interface{}{"content": content, "updated_at": now, "edited_at": now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostTag{}).Error; err != nil {
+			return err
+		}
+		// Hashtags from the comments stay attached to the post
+		hashtags, err := postHashtags(tx, post.ID, content)
+		if err != nil {
+			return err
+		}
+		return savePostTags(tx, post.ID, nil, hashtags)
+	})
+	if err != nil {
+		log.Printf("Error updating post %s: %v\n", post.ID, err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to update post", err)
+	}
+	if verdict.Flagged() {
+		go moderation.FlagContent(db, models.ReportTargetPost, post.ID.String(), post.UserID, verdict.Labels)
+	}
+
+	go tagPostAsync(db, post.ID, content)
+	go linkpreview.Prefetch(db, content)
+	go notifyMentions(db, post.UserID, newMentions(post.Content, content), "post", post.ID)
+
+	post.Content, post.UpdatedAt, post.EditedAt = content, now, &now
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Post updated successfully", post)
+}
+
+// UpdatePostVisibility changes who can see the caller's post. Plain reposts of
+// a post that stops being public disappear from feeds along with it.
+func UpdatePostVisibility(c *fiber.Ctx) error {
+	db := database.DB
+
+	post, err := loadOwnPost(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	var req struct {
+		Visibility string `json:"visibility"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+	}
+	if req.Visibility == "" {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Visibility is required", nil)
+	}
+	visibility, err := parseVisibility(req.Visibility, post.CommunityID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	if err := db.Table("posts").Where("id = ?", post.ID).Update("visibility", visibility).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to update post visibility", err)
+	}
+
+	post.Visibility = visibility
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Post visibility updated successfully", post)
+}
+
+// DeletePost soft-deletes the caller's post. Likes, comments and shares stay in
+// place but are no longer reachable, and the media objects are removed from storage.
+func DeletePost(c *fiber.Ctx) error {
+	db := database.DB
+
+	post, err := loadOwnPost(c, db)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	var objects []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		objects, err = deletePost(tx, post, time.Now())
+		return err
+	})
+	if err != nil {
+		log.Printf("Error deleting post %s: %v\n", post.ID, err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to delete post", err)
+	}
+
+	go deleteAttachmentObjects(objects)
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Post deleted successfully", nil)
+}
+
+// deletePost soft-deletes the post and drops its attachments, returning the
+// stored objects to remove once tx has committed.
+func deletePost(tx *gorm.DB, post *models.Post, now time.Time) ([]string, error) {
+	objects, err := attachmentObjects(tx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Posts from before attachments existed only carry media_url
+	if len(objects) == 0 && post.MediaURL != "" {
+		objects = []string{post.MediaURL}
+	}
+
+	if err := tx.Table("posts").Where("id = ?", post.ID).
+		Updates(map[string]interface{}{"deleted_at": now, "media_url": ""}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostAttachment{}).Error; err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// ContentRemover deletes posts and comments for moderators exactly as their
+// authors' own deletions do. It is handed to moderation.SetContentRemover,
+// since moderation cannot import this package.
+type ContentRemover struct{}
+
+// RemovePost deletes a live post inside tx; the returned function removes its
+// media from storage and must only run after tx commits.
+func (ContentRemover) RemovePost(tx *gorm.DB, postID uuid.UUID, now time.Time) (func(), error) {
+	var post models.Post
+	if err := tx.Table("posts").Where("id = ? AND deleted_at IS NULL", postID).First(&post).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return func() {}, nil
+		}
+		return nil, err
+	}
+	objects, err := deletePost(tx, &post, now)
+	if err != nil {
+		return nil, err
+	}
+	return func() { go deleteAttachmentObjects(objects) }, nil
+}
+
+// RemoveComment deletes a comment and its replies inside tx.
+func (ContentRemover) RemoveComment(tx *gorm.DB, commentID uuid.UUID, now time.Time) error {
+	var postID uuid.UUID
+	if err := tx.Table("comments").Where("id = ?", commentID).Select("post_id").Scan(&postID).Error; err != nil {
+		return err
+	}
+	if err := deleteCommentTree(tx, commentID, now); err != nil {
+		return err
+	}
+	if postID == uuid.Nil {
+		return nil
+	}
+	return syncHashtags(tx, postID)
+}
+
+// GetPostHistory lists the previous versions of a post, newest first.
+func GetPostHistory(c *fiber.Ctx) error {
+	db := database.DB
+
+	postID, err := uuid.Parse(c.Params("post_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid post ID format", err)
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	viewerID, err := uuid.Parse(userID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", err)
+	}
+	post, err := loadVisiblePost(db, viewerID, postID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	edits := []models.PostEdit{}
+	if err := db.Where("post_id = ?", postID).Order("edited_at DESC, id DESC").Find(&edits).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch edit history", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Edit history fetched successfully", fiber.Map{
+		"post_id":   post.ID,
+		"content":   post.Content,
+		"edited_at": post.EditedAt,
+		"edits":     edits,
+	})
+}
+
+// loadOwnPost loads the live post named by :post_id and checks the caller
+// wrote it.
+func loadOwnPost(c *fiber.Ctx, db *gorm.DB) (*models.Post, error) {
+	userId, ok := c.Locals("user_id").(string)
+	if !ok || userId == "" {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or missing user_id")
+	}
+
+	postID, err := uuid.Parse(c.Params("post_id"))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid post ID format")
+	}
+
+	var post models.Post
+	if err := db.Table("posts").Where("id = ? AND deleted_at IS NULL", postID).First(&post).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Post not found")
+		}
+		return nil, err
+	}
+
+	if post.UserID.String() != userId {
+		return nil, fiber.NewError(fiber.StatusForbidden, "You can only modify your own posts")
+	}
+	return &post, nil
+}
+
+// deleteFromSupabase removes an object previously returned by uploadToSupabase.
+func deleteFromSupabase(publicURL string) error {
+	bucketName := "file-buckets"
+	apiURL := os.Getenv("STORAGE_URL")
+	authToken := "Bearer " + os.Getenv("SERVICE_ROLE_SECRET")
+
+	if apiURL == "" {
+		return fmt.Errorf("STORAGE_URL is not set in the environment variables")
+	}
+
+	prefix := fmt.Sprintf("%s/object/public/%s/", apiURL, bucketName)
+	if !strings.HasPrefix(publicURL, prefix) {
+		return fmt.Errorf("media URL %s is not in the storage bucket", publicURL)
+	}
+	objectPath := strings.TrimPrefix(publicURL, prefix)
+	requestURL := fmt.Sprintf("%s/object/%s/%s", apiURL, bucketName, objectPath)
+
+	req, err := http.NewRequest("DELETE", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", authToken)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delete failed with status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package posts
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/feed"
+	"Backend/src/modules/notifications"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxHashtags      = 10
+	maxHashtagLength = 50
+	maxMentions      = 20
+)
+
+var (
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.]{1,30})`)
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{M}\p{N}_&#])#([\p{L}\p{M}\p{N}_]+)`)
+	// Links are skipped so paths like /@name and fragments like /#top are not picked up
+	linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+)
+
+// ParseMentions returns the distinct usernames mentioned as @username in content.
+func ParseMentions(content string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(withoutLinks(content), -1) {
+		username := strings.TrimRight(match[1], ".")
+		key := strings.ToLower(username)
+		if username == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, username)
+		if len(usernames) == maxMentions {
+			break
+		}
+	}
+	return usernames
+}
+
+// ParseHashtags returns the distinct lowercased #hashtags in content.
+func ParseHashtags(content string) []string {
+	var hashtags []string
+	seen := make(map[string]bool)
+	for _, match := range hashtagPattern.FindAllStringSubmatch(withoutLinks(content), -1) {
+		tag := strings.ToLower(match[1])
+		if len([]rune(tag)) > maxHashtagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		hashtags = append(hashtags, tag)
+		if len(hashtags) == maxHashtags {
+			break
+		}
+	}
+	return hashtags
+}
+
+// withoutLinks blanks out the links in content.
+func withoutLinks(content string) string {
+	return linkPattern.ReplaceAllString(content, " ")
+}
+
+// newMentions returns the usernames mentioned in newContent but not in oldContent.
+func newMentions(oldContent, newContent string) []string {
+	previous := make(map[string]bool)
+	for _, username := range ParseMentions(oldContent) {
+		previous[strings.ToLower(username)] = true
+	}
+	var added []string
+	for _, username := range ParseMentions(newContent) {
+		if !previous[strings.ToLower(username)] {
+			added = append(added, username)
+		}
+	}
+	return added
+}
+
+// notifyMentions notifies the mentioned users, other than the author, who can
+// see the post. kind names what the mention appeared in ("post" or "comment").
+func notifyMentions(db *gorm.DB, authorID uuid.UUID, usernames []string, kind string, postID uuid.UUID) {
+	if len(usernames) == 0 {
+		return
+	}
+	lowered := make([]string, len(usernames))
+	for i, username := range usernames {
+		lowered[i] = strings.ToLower(username)
+	}
+
+	// Only users who may see the post are told about it
+	var userIDs []uuid.UUID
+	if err := db.Table("users").
+		Where("LOWER(users.username) IN (?) AND users.id <> ?", lowered, authorID).
+		Where("EXISTS (SELECT 1 FROM posts WHERE posts.id = ? AND "+feed.VisiblePostsClause+")", postID, gorm.Expr("users.id")).
+		Pluck("users.id", &userIDs).Error; err != nil {
+		log.Printf("Error resolving mentions for post %s: %v\n", postID, err)
+		return
+	}
+
+	var authorName string
+	if err := db.Table("users").Where("id = ?", authorID).Select("username").Scan(&authorName).Error; err != nil {
+		log.Printf("Error fetching username of %s: %v\n", authorID, err)
+		return
+	}
+
+	message := fmt.Sprintf("%s mentioned you in a %s", authorName, kind)
+	if err := notifications.NotifyUsers(db, userIDs, "mention", "New mention", message); err != nil {
+		log.Printf("Error notifying mentions for post %s: %v\n", postID, err)
+	}
+}
+
+// GetPostsByHashtag lists the visible posts carrying a tag, newest first. Pass
+// ?source=hashtag to only include posts whose author wrote the hashtag.
+func GetPostsByHashtag(c *fiber.Ctx) error {
+	db := database.DB
+
+	userId, ok := c.Locals("user_id").(string)
+	if !ok || userId == "" {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", nil)
+	}
+	userID, err := uuid.Parse(userId)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid user ID format", err)
+	}
+
+	tag := strings.ToLower(strings.TrimPrefix(c.Params("tag"), "#"))
+	if tag == "" {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Hashtag is required", nil)
+	}
+
+	limit, offset := helpers.ParsePagination(c, 10, 50)
+
+	taggedPosts := db.Table("post_tags").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("LOWER(tags.tag) = ?", tag).
+		Select("post_tags.post_id")
+	if source := c.Query("source"); source != "" {
+		if source != models.TagSourceHashtag && source != models.TagSourcePredicted {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Source must be hashtag or predicted", nil)
+		}
+		taggedPosts = taggedPosts.Where("post_tags.source = ?", source)
+	}
+
+	var posts []models.Post
+	if err := feed.PostQuery(db, userID).
+		Where("posts.id IN (?)", taggedPosts).
+		Order("posts.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&posts).Error; err != nil {
+		log.Printf("Error fetching posts for hashtag %s: %v\n", tag, err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch posts", err)
+	}
+
+	feedPosts := feed.BuildFeedPosts(posts)
+	if err := feed.ApplyViewerState(userID, feedPosts); err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch posts", err)
+	}
+
+	feed.RecordImpressions(userID, feedPosts)
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Posts fetched successfully", fiber.Map{
+		"tag":    tag,
+		"limit":  limit,
+		"offset": offset,
+		"posts":  feedPosts,
+	})
+}
+
+// GetTrendingHashtags returns the user-authored hashtags used most in the last ?days (default 7).
+func GetTrendingHashtags(c *fiber.Ctx) error {
+	db := database.DB
+
+	days := c.QueryInt("days", 7)
+	if days <= 0 || days > 90 {
+		days = 7
+	}
+	limit, _ := helpers.ParsePagination(c, 10, 50)
+
+	type TrendingHashtag struct {
+		Tag       string `json:"tag"`
+		PostCount int    `json:"post_count"`
+	}
+	trending := []TrendingHashtag{}
+	if err := db.Table("post_tags").
+		Select("tags.tag, COUNT(DISTINCT post_tags.post_id) AS post_count").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Joins("JOIN posts ON posts.id = post_tags.post_id").
+		Where("post_tags.source = ? AND posts.deleted_at IS NULL AND posts.hidden_at IS NULL", models.TagSourceHashtag).
+		Where("posts.created_at >= NOW() - make_interval(days => ?)", days).
+		Where("posts.community_id IS NULL OR posts.community_id IN (SELECT id FROM communities WHERE visibility = 'public')").
+		Where("posts.visibility = ?", models.PostVisibilityPublic).
+		Group("tags.tag").
+		Order("post_count DESC, tags.tag ASC").
+		Limit(limit).
+		Scan(&trending).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch trending hashtags", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Trending hashtags fetched successfully", trending)
+}
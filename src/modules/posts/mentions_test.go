@@ -0,0 +1,98 @@
+package posts
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"none", "hello world", nil},
+		{"single", "hi @alice", []string{"alice"}},
+		{"start of text", "@bob thoughts?", []string{"bob"}},
+		{"dots and underscores", "cc @jane.doe_99", []string{"jane.doe_99"}},
+		{"trailing punctuation", "thanks @alice. and @bob, also @carol!", []string{"alice", "bob", "carol"}},
+		{"trailing dots", "ask @dave...", []string{"dave"}},
+		{"duplicates", "@Alice and @alice and @ALICE", []string{"Alice"}},
+		{"email", "mail bob@example.com", nil},
+		{"double at", "@@alice", nil},
+		{"in parentheses", "(@alice)", []string{"alice"}},
+		{"in a URL path", "see https://example.com/@alice", nil},
+		{"in a www link", "see www.example.com/@alice", nil},
+		{"after a URL", "https://example.com/x @bob", []string{"bob"}},
+		{"longer than a username", "@" + strings.Repeat("a", 31), []string{strings.Repeat("a", 30)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMentions(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMentionsLimit(t *testing.T) {
+	var content []string
+	for i := 0; i < maxMentions+5; i++ {
+		content = append(content, "@user"+strings.Repeat("x", i))
+	}
+	if got := ParseMentions(strings.Join(content, " ")); len(got) != maxMentions {
+		t.Errorf("ParseMentions returned %d usernames, want %d", len(got), maxMentions)
+	}
+}
+
+func TestParseHashtags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"none", "hello world", nil},
+		{"single", "loving #golang", []string{"golang"}},
+		{"lowercased", "#GoLang", []string{"golang"}},
+		{"trailing punctuation", "#go, #rust! #zig.", []string{"go", "rust", "zig"}},
+		{"duplicates", "#go #Go #GO", []string{"go"}},
+		{"unicode", "#café #日本語 #हिन्दी", []string{"café", "日本語", "हिन्दी"}},
+		{"digits and underscores", "#web_3 #2024", []string{"web_3", "2024"}},
+		{"inside a word", "C#sharp", nil},
+		{"html entity", "&#39;quoted&#39;", nil},
+		{"double hash", "##go", nil},
+		{"in a URL fragment", "read https://example.com/#section", nil},
+		{"in a URL path", "https://example.com/page#top", nil},
+		{"in a www link", "www.example.com/#section", nil},
+		{"after a URL", "https://example.com #news", []string{"news"}},
+		{"too long", "#" + strings.Repeat("a", maxHashtagLength+1), nil},
+		{"longest allowed", "#" + strings.Repeat("a", maxHashtagLength), []string{strings.Repeat("a", maxHashtagLength)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseHashtags(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHashtags(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHashtagsLimit(t *testing.T) {
+	var content []string
+	for i := 0; i < maxHashtags+5; i++ {
+		content = append(content, "#tag"+strings.Repeat("x", i))
+	}
+	if got := ParseHashtags(strings.Join(content, " ")); len(got) != maxHashtags {
+		t.Errorf("ParseHashtags returned %d tags, want %d", len(got), maxHashtags)
+	}
+}
+
+func TestNewMentions(t *testing.T) {
+	got := newMentions("hi @alice and @bob", "hi @Alice, @bob and @carol")
+	if want := []string{"carol"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("newMentions = %q, want %q", got, want)
+	}
+}
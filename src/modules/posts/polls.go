@@ -0,0 +1,269 @@
+package posts
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/feed"
+	"Backend/src/modules/notifications"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxPollOptionLength = 100
+	pollCloseInterval   = time.Minute
+)
+
+// parsePoll reads the poll settings of a poll post from the form: poll_options (a JSON
+// array of strings), poll_multiple_choice and the optional RFC 3339 poll_closes_at.
+func parsePoll(c *fiber.Ctx) (*models.Poll, []models.PollOption, error) {
+	var texts []string
+	if err := json.Unmarshal([]byte(c.FormValue("poll_options")), &texts); err != nil {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "poll_options must be a JSON array of strings")
+	}
+	if len(texts) < models.PollMinOptions || len(texts) > models.PollMaxOptions {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("A poll needs between %d and %d options", models.PollMinOptions, models.PollMaxOptions))
+	}
+
+	seen := make(map[string]bool, len(texts))
+	options := make([]models.PollOption, len(texts))
+	for i, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" || len([]rune(text)) > maxPollOptionLength {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Poll options must be between 1 and %d characters", maxPollOptionLength))
+		}
+		if seen[strings.ToLower(text)] {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Poll options must be distinct")
+		}
+		seen[strings.ToLower(text)] = true
+		options[i] = models.PollOption{Position: i, Text: text}
+	}
+
+	poll := &models.Poll{MultipleChoice: c.FormValue("poll_multiple_choice") == "true"}
+	if closesAt := c.FormValue("poll_closes_at"); closesAt != "" {
+		t, err := time.Parse(time.RFC3339, closesAt)
+		if err != nil {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "poll_closes_at must be an RFC 3339 timestamp")
+		}
+		if !t.After(time.Now()) {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "poll_closes_at must be in the future")
+		}
+		poll.ClosesAt = &t
+	}
+	return poll, options, nil
+}
+
+// createPoll stores the poll of a newly created post.
+func createPoll(tx *gorm.DB, postID uuid.UUID, poll *models.Poll, options []models.PollOption) error {
+	poll.PostID = postID
+	if err := tx.Create(poll).Error; err != nil {
+		return err
+	}
+	for i := range options {
+		options[i].PostID = postID
+	}
+	return tx.Create(&options).Error
+}
+
+// GetPoll returns the live results of a poll, including the caller's own votes.
+func GetPoll(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, postID, err := parsePollParams(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	if _, err := loadVisiblePost(db, userID, postID); err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	result, err := pollResult(userID, postID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Poll fetched successfully", result)
+}
+
+// VotePoll records the caller's vote. Each user votes once: one option on a
+// single-choice poll, or any set of options on a multiple-choice poll.
+func VotePoll(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, postID, err := parsePollParams(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	var req struct {
+		OptionIDs []int `json:"option_ids"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+	}
+	if len(req.OptionIDs) == 0 {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Choose at least one option", nil)
+	}
+
+	if _, err := loadVisiblePost(db, userID, postID); err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Lock the poll so concurrent ballots from the same user serialize
+		var poll models.Poll
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("post_id = ?", postID).First(&poll).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, "This post has no poll")
+			}
+			return err
+		}
+		if poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now()) {
+			return fiber.NewError(fiber.StatusConflict, "This poll is closed")
+		}
+		if !poll.MultipleChoice && len(req.OptionIDs) > 1 {
+			return fiber.NewError(fiber.StatusBadRequest, "This poll allows a single choice")
+		}
+
+		var voted bool
+		if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM poll_votes WHERE post_id = ? AND user_id = ?)", postID, userID).
+			Scan(&voted).Error; err != nil {
+			return err
+		}
+		if voted {
+			return fiber.NewError(fiber.StatusConflict, "You have already voted in this poll")
+		}
+
+		var validIDs []int
+		if err := tx.Model(&models.PollOption{}).Where("post_id = ? AND id IN (?)", postID, req.OptionIDs).
+			Pluck("id", &validIDs).Error; err != nil {
+			return err
+		}
+		if len(validIDs) != len(req.OptionIDs) {
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid or duplicate poll option")
+		}
+
+		votes := make([]models.PollVote, len(validIDs))
+		for i, optionID := range validIDs {
+			votes[i] = models.PollVote{PostID: postID, OptionID: optionID, UserID: userID}
+		}
+		return tx.Create(&votes).Error
+	})
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	result, err := pollResult(userID, postID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Vote recorded successfully", result)
+}
+
+// pollResult loads the results of one poll as the user sees them.
+func pollResult(userID, postID uuid.UUID) (*feed.PollResult, error) {
+	posts := []feed.FeedPost{{ID: postID.String()}}
+	polls, err := feed.RetrievePolls([]uuid.UUID{postID})
+	if err != nil {
+		return nil, err
+	}
+	if polls[postID] == nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "This post has no poll")
+	}
+	posts[0].Poll = polls[postID]
+	if err := feed.ApplyViewerState(userID, posts); err != nil {
+		return nil, err
+	}
+	return posts[0].Poll, nil
+}
+
+func parsePollParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	userID, err := helpers.CurrentUserID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	postID, err := uuid.Parse(c.Params("post_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid post ID format")
+	}
+	return userID, postID, nil
+}
+
+// ClosePolls runs forever, closing polls whose closing time has passed and
+// notifying their authors of the final results.
+func ClosePolls() {
+	ticker := time.NewTicker(pollCloseInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := closeDuePolls(database.DB); err != nil {
+			log.Printf("Error closing polls: %v\n", err)
+		}
+	}
+}
+
+// closeDuePolls claims the due polls in one statement, so each is announced once
+// even when several instances run the closer.
+func closeDuePolls(db *gorm.DB) error {
+	var closed []struct {
+		PostID uuid.UUID
+		UserID uuid.UUID
+		Live   bool
+	}
+	// closes_at is written by the application, so compare against its clock too
+	now := time.Now()
+	if err := db.Raw(`
+		UPDATE polls SET closed_at = ?
+		FROM posts
+		WHERE posts.id = polls.post_id AND polls.closed_at IS NULL AND polls.closes_at <= ?
+		RETURNING polls.post_id, posts.user_id, posts.deleted_at IS NULL AS live
+	`, now, now).Scan(&closed).Error; err != nil {
+		return err
+	}
+	if len(closed) == 0 {
+		return nil
+	}
+
+	postIDs := make([]uuid.UUID, len(closed))
+	for i, poll := range closed {
+		postIDs[i] = poll.PostID
+	}
+	results, err := feed.RetrievePolls(postIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, poll := range closed {
+		result := results[poll.PostID]
+		if result == nil || !poll.Live {
+			continue
+		}
+		message := fmt.Sprintf("Your poll has closed with %d voters", result.TotalVoters)
+		if leader := leadingOption(result); leader != nil {
+			message += fmt.Sprintf(". Top answer: %s (%d votes)", leader.Text, leader.Votes)
+		}
+		if err := notifications.NotifyUsers(db, []uuid.UUID{poll.UserID}, "poll_closed", "Poll closed", message); err != nil {
+			log.Printf("Error notifying author of poll %s: %v\n", poll.PostID, err)
+		}
+	}
+	return nil
+}
+
+// leadingOption returns the option with the most votes, or nil when nobody voted.
+func leadingOption(result *feed.PollResult) *feed.PollOptionResult {
+	var leader *feed.PollOptionResult
+	for i := range result.Options {
+		if result.Options[i].Votes > 0 && (leader == nil || result.Options[i].Votes > leader.Votes) {
+			leader = &result.Options[i]
+		}
+	}
+	return leader
+}
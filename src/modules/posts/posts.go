@@ -4,18 +4,28 @@ import (
 	"Backend/src/core/database"
 	"Backend/src/core/helpers"
 	"Backend/src/core/models"
+	"Backend/src/modules/communities"
+	"Backend/src/modules/feed"
+	"Backend/src/modules/linkpreview"
+	"Backend/src/modules/moderation"
+	"Backend/src/modules/notifications"
 	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func CreatePost(c *fiber.Ctx) error {
@@ -39,133 +49,281 @@ func CreatePost(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusBadRequest, "Post content cannot be empty", nil)
 	}
 
-	var mediaURL string
-	media, err := c.FormFile("media")
-	if err == nil {
-		mediaContent, err := media.Open()
-		if err != nil {
-			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to open media file", err)
+	postType := c.FormValue("type", models.PostTypePost)
+	if postType != models.PostTypePost && postType != models.PostTypeAnnouncement && postType != models.PostTypePoll {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Post type must be post, announcement or poll", nil)
+	}
+
+	var poll *models.Poll
+	var pollOptions []models.PollOption
+	if postType == models.PostTypePoll {
+		if poll, pollOptions, err = parsePoll(c); err != nil {
+			return helpers.HandleFiberError(c, err, "Failed to fetch post")
 		}
-		defer mediaContent.Close()
+	}
 
-		fileName := uuid.New().String() + "-" + media.Filename
-		mediaURL, err = uploadToSupabase(fileName, mediaContent)
+	var communityID *int
+	if communityIDStr := c.FormValue("community_id"); communityIDStr != "" {
+		id, err := strconv.Atoi(communityIDStr)
 		if err != nil {
-			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to upload media", err)
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid community ID format", err)
 		}
-	} else if err != http.ErrMissingFile {
-		log.Printf("Media upload error: %v\n", err)
-		return helpers.HandleError(c, fiber.StatusInternalServerError, "Unexpected media upload error", err)
+		communityID = &id
+	}
+	community, err := resolvePostCommunity(db, userID, communityID, postType)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	visibility, err := parseVisibility(c.FormValue("visibility"), communityID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
 	}
 
-	tags, err := getPredictedTags(content)
+	processed, err := parseAttachments(c)
 	if err != nil {
-		log.Printf("Error predicting tags: %v\n", err)
-		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to predict tags", err)
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	attachments, err := uploadAttachments(processed)
+	if err != nil {
+		log.Printf("Media upload error: %v\n", err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to upload media", err)
 	}
 
 	post := models.Post{
-		UserID:        userID,
-		Content:       content,
-		MediaURL:      mediaURL,
-		LikesCount:    0,
-		CommentsCount: 0,
+		UserID:     userID,
+		Content:    content,
+		PostType:   postType,
+		Visibility: visibility,
 	}
-	if err := db.Table("posts").Create(&post).Error; err != nil {
+	if err := publishPost(db, &post, community, attachments, poll, pollOptions); err != nil {
 		log.Printf("Error creating post: %v\n", err)
+		go deleteAttachmentObjects(attachmentURLs(attachments))
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return helpers.HandleError(c, fiberErr.Code, fiberErr.Message, err)
+		}
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create post", err)
 	}
 
-	var postTags []models.PostTag
-	for _, tag := range tags {
-		var tagID int
+	log.Printf("Post created successfully: %+v\n", post)
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Post created successfully", post)
+}
 
-		err := db.Table("tags").Where("tag = ?", tag).Select("id").Scan(&tagID).Error
-		if err != nil {
-			log.Printf("Error finding tag ID for tag %s: %v\n", tag, err)
-			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to find tag ID", err)
+// resolvePostCommunity checks that the author may publish a post of postType into
+// the community: authors must be members and only admins may announce.
+func resolvePostCommunity(db *gorm.DB, userID uuid.UUID, communityID *int, postType string) (*models.Community, error) {
+	if communityID == nil {
+		if postType == models.PostTypeAnnouncement {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Announcements must be posted to a community")
 		}
+		return nil, nil
+	}
 
-		if tagID == 0 {
-			err := db.Table("tags").Create(&models.Tag{Tag: tag}).Error
-			if err != nil {
-				log.Printf("Error inserting tag %s: %v\n", tag, err)
-				return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to insert tag", err)
-			}
+	member, err := communities.GetMembership(db, *communityID, userID)
+	if errors.Is(err, communities.ErrNotMember) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "You are not a member of this community")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if postType == models.PostTypeAnnouncement && communities.RoleRank(member.Role) < communities.RoleRank(models.CommunityRoleAdmin) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "Only community admins can post announcements")
+	}
 
-			err = db.Table("tags").Where("tag = ?", tag).Select("id").Scan(&tagID).Error
-			if err != nil {
-				log.Printf("Error finding new tag ID for tag %s: %v\n", tag, err)
-				return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to find new tag ID", err)
-			}
+	var community models.Community
+	if err := db.First(&community, *communityID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Community not found")
 		}
+		return nil, err
+	}
+	return &community, nil
+}
 
-		var exists bool
-		err = db.Table("post_tags").
-			Select("exists (select 1 from post_tags where post_id = ? and tag_id = ?)", post.ID, tagID).
-			Scan(&exists).Error
-		if err != nil {
-			log.Printf("Error checking for duplicate tag entry: %v\n", err)
-			return helpers.HandleError(c, fiber.StatusInternalServerError, "Error checking for duplicate tag entry", err)
+// parseVisibility validates a requested post visibility, defaulting to public.
+// Community visibility needs the post to be published into a community.
+func parseVisibility(value string, communityID *int) (string, error) {
+	switch value {
+	case "":
+		return models.PostVisibilityPublic, nil
+	case models.PostVisibilityPublic, models.PostVisibilityFollowers, models.PostVisibilityMutual, models.PostVisibilityPrivate:
+		return value, nil
+	case models.PostVisibilityCommunity:
+		if communityID == nil {
+			return "", fiber.NewError(fiber.StatusBadRequest, "Community visibility needs a community_id")
 		}
+		return value, nil
+	}
+	return "", fiber.NewError(fiber.StatusBadRequest, "Visibility must be public, followers, mutual, community or private")
+}
 
-		if !exists {
+// publishPost screens a validated post with the text filter and stores it with
+// its uploaded attachments and poll, then tags it and sends the announcement
+// and mention notifications. The post is filled in with its ID, attachments
+// and filtered content. Blocked content fails with moderation.ErrContentBlocked.
+func publishPost(db *gorm.DB, post *models.Post, community *models.Community, attachments []models.PostAttachment, poll *models.Poll, pollOptions []models.PollOption) error {
+	// media_url keeps pointing at the first attachment for older clients
+	if len(attachments) > 0 {
+		post.MediaURL = attachments[0].URL
+	}
+	if community != nil {
+		post.CommunityID = &community.ID
+	}
+	verdict, err := screenContent(post.Content)
+	if err != nil {
+		return err
+	}
+	post.Content = verdict.Text
 
-			postTags = append(postTags, models.PostTag{
-				PostID: post.ID,
-				TagID:  tagID,
-			})
-		} else {
-			log.Printf("Post tag entry already exists for post %s and tag %d\n", post.ID, tagID)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("posts").Create(post).Error; err != nil {
+			return err
+		}
+		for i := range attachments {
+			attachments[i].ID = 0
+			attachments[i].PostID = post.ID
+		}
+		post.Attachments = attachments
+		if len(attachments) > 0 {
+			if err := tx.Create(&post.Attachments).Error; err != nil {
+				return err
+			}
 		}
+		if poll != nil {
+			return createPoll(tx, post.ID, poll, pollOptions)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if verdict.Flagged() {
+		go moderation.FlagContent(db, models.ReportTargetPost, post.ID.String(), post.UserID, verdict.Labels)
 	}
 
-	if len(postTags) > 0 {
-		if err := db.Table("post_tags").Create(&postTags).Error; err != nil {
-			log.Printf("Error creating post tags: %v\n", err)
-			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create post tags", err)
-		}
+	if err := savePostTags(db, post.ID, nil, ParseHashtags(post.Content)); err != nil {
+		log.Printf("Error saving post tags: %v\n", err)
 	}
+	go tagPostAsync(db, post.ID, post.Content)
+	go linkpreview.Prefetch(db, post.Content)
 
-	log.Printf("Post created successfully: %+v\n", post)
-	return helpers.HandleSuccess(c, fiber.StatusOK, "Post created successfully", post)
+	if post.PostType == models.PostTypeAnnouncement && community != nil {
+		go notifyCommunityAnnouncement(db, *post, *community)
+	}
+	go notifyMentions(db, post.UserID, ParseMentions(post.Content), "post", post.ID)
+	return nil
 }
 
-func getPredictedTags(content string) ([]string, error) {
-	if content == "" {
-		log.Println("Content is empty; cannot predict tags.")
-		return nil, fmt.Errorf("content cannot be empty for tag prediction")
+// screenContent runs text through the moderation text filter. Blocked text
+// fails with moderation.ErrContentBlocked.
+func screenContent(text string) (moderation.Verdict, error) {
+	verdict := moderation.Screen(context.Background(), text)
+	if verdict.Blocked() {
+		return verdict, moderation.ErrContentBlocked
 	}
-	modelURL := "https://ml-models-1rr1.onrender.com/predict"
-	requestData := map[string]string{"content": content}
-	requestBody, err := json.Marshal(requestData)
-	if err != nil {
-		log.Printf("Error marshaling request data: %v\n", err)
-		return nil, err
+	return verdict, nil
+}
+
+// notifyCommunityAnnouncement notifies every member of the community except the author.
+func notifyCommunityAnnouncement(db *gorm.DB, post models.Post, community models.Community) {
+	var memberIDs []uuid.UUID
+	if err := db.Table("community_members").
+		Where("community_id = ? AND user_id <> ?", community.ID, post.UserID).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		log.Printf("Error fetching members of community %d: %v\n", community.ID, err)
+		return
 	}
 
-	resp, err := http.Post(modelURL, "application/json", bytes.NewBuffer(requestBody))
-	if err != nil {
-		log.Printf("Error calling tag prediction model: %v\n", err)
-		return nil, err
+	preview := post.Content
+	if runes := []rune(preview); len(runes) > 100 {
+		preview = string(runes[:100]) + "..."
 	}
-	defer resp.Body.Close()
+	message := fmt.Sprintf("New announcement in %s: %s", community.Name, preview)
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Tag prediction model responded with status: %d\n", resp.StatusCode)
-		return nil, fmt.Errorf("failed to fetch predicted tags")
+	if err := notifications.NotifyUsers(db, memberIDs, "announcement", community.Name, message); err != nil {
+		log.Printf("Error notifying members of community %d: %v\n", community.ID, err)
+	}
+}
+
+// savePostTags links the post to its user-authored hashtags and predicted tags,
+// creating tags that do not exist yet. A tag that is both keeps the hashtag
+// source, and tags the post already carries are left untouched.
+func savePostTags(db *gorm.DB, postID uuid.UUID, predicted, hashtags []string) error {
+	var postTags []models.PostTag
+	seen := make(map[int]bool)
+	add := func(tags []string, source string) error {
+		for _, tag := range tags {
+			var tagID int
+			if err := db.Table("tags").Where("tag = ?", tag).Select("id").Scan(&tagID).Error; err != nil {
+				return fmt.Errorf("failed to find tag ID for tag %s: %w", tag, err)
+			}
+
+			if tagID == 0 {
+				newTag := models.Tag{Tag: tag}
+				if err := db.Table("tags").Create(&newTag).Error; err != nil {
+					return fmt.Errorf("failed to insert tag %s: %w", tag, err)
+				}
+				tagID = newTag.ID
+			}
+
+			if seen[tagID] {
+				continue
+			}
+			seen[tagID] = true
+			postTags = append(postTags, models.PostTag{PostID: postID, TagID: tagID, Source: source})
+		}
+		return nil
 	}
 
-	var response struct {
-		Tags []string `json:"tags"`
+	if err := add(hashtags, models.TagSourceHashtag); err != nil {
+		return err
+	}
+	if err := add(predicted, models.TagSourcePredicted); err != nil {
+		return err
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		log.Printf("Error decoding model response: %v\n", err)
+
+	if len(postTags) == 0 {
+		return nil
+	}
+	return db.Table("post_tags").Clauses(clause.OnConflict{DoNothing: true}).Create(&postTags).Error
+}
+
+// postHashtags collects the hashtags written in the post's content and in its
+// live comments.
+func postHashtags(db *gorm.DB, postID uuid.UUID, content string) ([]string, error) {
+	var comments []string
+	if err := db.Table("comments").Where("post_id = ? AND deleted_at IS NULL", postID).
+		Order("created_at").Pluck("content", &comments).Error; err != nil {
 		return nil, err
 	}
+	hashtags := ParseHashtags(content)
+	for _, comment := range comments {
+		hashtags = append(hashtags, ParseHashtags(comment)...)
+	}
+	return hashtags, nil
+}
 
-	log.Printf("Predicted tags: %v\n", response.Tags)
-	return response.Tags, nil
+// syncHashtags brings the post's hashtag tags back in line with the post and
+// its live comments after a comment is written, edited or deleted.
+func syncHashtags(db *gorm.DB, postID uuid.UUID) error {
+	var content string
+	if err := db.Table("posts").Where("id = ?", postID).Select("content").Scan(&content).Error; err != nil {
+		return err
+	}
+	hashtags, err := postHashtags(db, postID, content)
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		stale := tx.Where("post_id = ? AND source = ?", postID, models.TagSourceHashtag)
+		if len(hashtags) > 0 {
+			stale = stale.Where("tag_id NOT IN (SELECT id FROM tags WHERE tag IN ?)", hashtags)
+		}
+		if err := stale.Delete(&models.PostTag{}).Error; err != nil {
+			return err
+		}
+		return savePostTags(tx, postID, nil, hashtags)
+	})
 }
 
 func uploadToSupabase(fileName string, fileContent io.Reader) (string, error) {
@@ -231,28 +389,60 @@ func CreateComment(c *fiber.Ctx) error {
 	}
 
 	type Request struct {
-		PostID  string `json:"post_id" validate:"required,uuid"`
-		Content string `json:"content" validate:"required"`
+		PostID   string `json:"post_id" validate:"required,uuid"`
+		ParentID string `json:"parent_id" validate:"omitempty,uuid"`
+		Content  string `json:"content" validate:"required"`
 	}
 	var req Request
 	if err := c.BodyParser(&req); err != nil {
 		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
 	}
+	req.Content = strings.TrimSpace(req.Content)
+	if err := helpers.Validate(req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "A valid post_id and non-empty content are required", err)
+	}
 
-	var postExists bool
-	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM posts WHERE id = ?)", req.PostID).Scan(&postExists).Error; err != nil || !postExists {
+	postExists, err := postVisible(db, userID, req.PostID)
+	if err != nil || !postExists {
 		return helpers.HandleError(c, fiber.StatusNotFound, "Post not found", err)
 	}
 
+	verdict, err := screenContent(req.Content)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
 	comment := models.Comment{
 		ID:      uuid.New(),
 		UserID:  uuid.MustParse(userID),
 		PostID:  uuid.MustParse(req.PostID),
-		Content: req.Content,
+		Content: verdict.Text,
+	}
+
+	// Replies must point at a live comment on the same post
+	if req.ParentID != "" {
+		parentID, err := uuid.Parse(req.ParentID)
+		if err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid parent comment ID format", err)
+		}
+		var parentExists bool
+		if err := db.Raw("SELECT EXISTS (SELECT 1 FROM comments WHERE id = ? AND post_id = ? AND deleted_at IS NULL)", parentID, req.PostID).
+			Scan(&parentExists).Error; err != nil || !parentExists {
+			return helpers.HandleError(c, fiber.StatusNotFound, "Parent comment not found", err)
+		}
+		comment.ParentID = &parentID
 	}
 	if err := db.Create(&comment).Error; err != nil {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create comment", err)
 	}
+	if verdict.Flagged() {
+		go moderation.FlagContent(db, models.ReportTargetComment, comment.ID.String(), comment.UserID, verdict.Labels)
+	}
+
+	if err := syncHashtags(db, comment.PostID); err != nil {
+		log.Printf("Error saving hashtags of comment %s: %v\n", comment.ID, err)
+	}
+	go notifyMentions(db, comment.UserID, ParseMentions(comment.Content), "comment", comment.PostID)
 
 	return helpers.HandleSuccess(c, fiber.StatusCreated, "Comment created successfully", comment)
 }
@@ -273,24 +463,30 @@ func CreateLike(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
 	}
 
-	var postExists bool
-	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM posts WHERE id = ?)", req.PostID).Scan(&postExists).Error; err != nil || !postExists {
+	postExists, err := postVisible(db, userID, req.PostID)
+	if err != nil || !postExists {
 		return helpers.HandleError(c, fiber.StatusNotFound, "Post not found", err)
 	}
 
-	var existingLike models.Like
-	if err := db.Where("user_id = ? AND post_id = ?", userID, req.PostID).First(&existingLike).Error; err == nil {
-		if err := db.Where("user_id = ? AND post_id = ?", userID, req.PostID).Delete(&models.Like{}).Error; err != nil {
-			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to remove like", err)
-		}
+	// The toggle only undoes a like; any other reaction is replaced by one
+	removed := db.Where("user_id = ? AND post_id = ? AND reaction_type = ?", userID, req.PostID, models.ReactionLike).Delete(&models.Like{})
+	if removed.Error != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to remove like", removed.Error)
+	}
+	if removed.RowsAffected > 0 {
 		return helpers.HandleSuccess(c, fiber.StatusOK, "Like removed successfully", nil)
 	}
 
 	like := models.Like{
-		UserID: uuid.MustParse(userID),
-		PostID: uuid.MustParse(req.PostID),
-	}
-	if err := db.Create(&like).Error; err != nil {
+		UserID:       uuid.MustParse(userID),
+		PostID:       uuid.MustParse(req.PostID),
+		ReactionType: models.ReactionLike,
+		CreatedAt:    time.Now(),
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reaction_type", "created_at"}),
+	}).Create(&like).Error; err != nil {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to create like", err)
 	}
 
@@ -310,11 +506,6 @@ func CreateShare(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
 	}
 
-	var exists bool
-	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM posts WHERE id = ?)", req.PostID).Scan(&exists).Error; err != nil || !exists {
-		return helpers.HandleError(c, fiber.StatusNotFound, "Post not found", err)
-	}
-
 	userID := c.Locals("user_id")
 	if userID == nil {
 		return helpers.HandleError(c, fiber.StatusUnauthorized, "User authentication failed: user_id is missing", nil)
@@ -331,6 +522,19 @@ func CreateShare(c *fiber.Ctx) error {
 
 	fmt.Println("Auth ID:", userIDParsed)
 
+	exists, err := postVisible(db, userIDParsed.String(), req.PostID)
+	if err != nil || !exists {
+		return helpers.HandleError(c, fiber.StatusNotFound, "Post not found", err)
+	}
+	// Sharing must not reveal a post to someone its visibility excludes
+	recipientCanSee, err := postVisible(db, req.ToUserID, req.PostID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to check post visibility", err)
+	}
+	if !recipientCanSee {
+		return helpers.HandleError(c, fiber.StatusForbidden, "The recipient is not allowed to see this post", nil)
+	}
+
 	var user models.User
 	if err := db.First(&user, "id = ?", userIDParsed).Error; err != nil {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "User not found", err)
@@ -348,6 +552,13 @@ func CreateShare(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to share post", err)
 	}
 
+	go func() {
+		message := fmt.Sprintf("%s shared a post with you", user.Username)
+		if err := notifications.NotifyUsers(db, []uuid.UUID{share.ToUserID}, "share", "Post shared with you", message); err != nil {
+			log.Printf("Error notifying share recipient %s: %v\n", share.ToUserID, err)
+		}
+	}()
+
 	return helpers.HandleSuccess(c, fiber.StatusCreated, "Post shared successfully", share)
 }
 
@@ -359,8 +570,9 @@ func GetLikesCount(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusBadRequest, "Missing post ID", nil)
 	}
 
-	var postExists bool
-	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM posts WHERE id = ?)", postID).Scan(&postExists).Error; err != nil || !postExists {
+	userID, _ := c.Locals("user_id").(string)
+	postExists, err := postVisible(db, userID, postID)
+	if err != nil || !postExists {
 		return helpers.HandleError(c, fiber.StatusNotFound, "Post not found", err)
 	}
 
@@ -369,9 +581,19 @@ func GetLikesCount(c *fiber.Ctx) error {
 		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to retrieve likes count", err)
 	}
 
+	parsedPostID, err := uuid.Parse(postID)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid post ID format", err)
+	}
+	reactionCounts, err := feed.RetrieveReactionCounts([]uuid.UUID{parsedPostID})
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to retrieve reaction counts", err)
+	}
+
 	response := map[string]interface{}{
-		"post_id":     postID,
-		"likes_count": likesCount,
+		"post_id":         postID,
+		"likes_count":     likesCount,
+		"reaction_counts": reactionCounts[parsedPostID],
 	}
 	return helpers.HandleSuccess(c, fiber.StatusOK, "Likes count retrieved successfully", response)
 }
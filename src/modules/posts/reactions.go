@@ -0,0 +1,152 @@
+package posts
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/feed"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// Reactor is a user who reacted to a post and how.
+type Reactor struct {
+	UserID        uuid.UUID `json:"user_id"`
+	Username      string    `json:"username"`
+	ProfilePicURL string    `json:"profile_pic_url"`
+	ReactionType  string    `json:"reaction_type"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// SetReaction records the caller's reaction to a post, replacing any earlier one.
+func SetReaction(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, postID, err := parseReactionParams(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	var req struct {
+		Type string `json:"type"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+	}
+	reactionType := strings.ToLower(strings.TrimSpace(req.Type))
+	if reactionType == "" {
+		reactionType = models.ReactionLike
+	}
+	if !models.IsValidReaction(reactionType) {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Reaction type must be one of: "+strings.Join(models.ReactionTypes, ", "), nil)
+	}
+
+	reaction := models.Like{
+		UserID:       userID,
+		PostID:       postID,
+		ReactionType: reactionType,
+		CreatedAt:    time.Now(),
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reaction_type", "created_at"}),
+	}).Create(&reaction).Error; err != nil {
+		log.Printf("Error saving reaction: %v\n", err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to save reaction", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Reaction saved successfully", reaction)
+}
+
+// RemoveReaction deletes the caller's reaction to a post.
+func RemoveReaction(c *fiber.Ctx) error {
+	db := database.DB
+
+	userID, postID, err := parseReactionParams(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	result := db.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.Like{})
+	if result.Error != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to remove reaction", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return helpers.HandleError(c, fiber.StatusNotFound, "You have not reacted to this post", nil)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Reaction removed successfully", nil)
+}
+
+// GetReactions lists who reacted to a post, newest first, optionally filtered
+// by ?type, along with the counts for every reaction type.
+func GetReactions(c *fiber.Ctx) error {
+	db := database.DB
+
+	_, postID, err := parseReactionParams(c)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+
+	limit, offset := helpers.ParsePagination(c, 20, 100)
+
+	query := db.Table("likes").
+		Select("likes.user_id, users.username, users.profile_pic_url, likes.reaction_type, likes.created_at").
+		Joins("JOIN users ON users.id = likes.user_id").
+		Where("likes.post_id = ?", postID)
+	if reactionType := c.Query("type"); reactionType != "" {
+		if !models.IsValidReaction(reactionType) {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Unknown reaction type", nil)
+		}
+		query = query.Where("likes.reaction_type = ?", reactionType)
+	}
+
+	reactors := []Reactor{}
+	if err := query.Order("likes.created_at DESC").Limit(limit).Offset(offset).Scan(&reactors).Error; err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch reactions", err)
+	}
+
+	counts, err := feed.RetrieveReactionCounts([]uuid.UUID{postID})
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to fetch reaction counts", err)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusOK, "Reactions fetched successfully", fiber.Map{
+		"post_id":  postID,
+		"counts":   counts[postID],
+		"limit":    limit,
+		"offset":   offset,
+		"reactors": reactors,
+	})
+}
+
+// parseReactionParams reads the caller and the live post named by :post_id.
+func parseReactionParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	userId, ok := c.Locals("user_id").(string)
+	if !ok || userId == "" {
+		return uuid.Nil, uuid.Nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid or missing user_id")
+	}
+	userID, err := uuid.Parse(userId)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format")
+	}
+
+	postID, err := uuid.Parse(c.Params("post_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid post ID format")
+	}
+
+	postExists, err := postVisible(database.DB, userID.String(), postID.String())
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if !postExists {
+		return uuid.Nil, uuid.Nil, fiber.NewError(fiber.StatusNotFound, "Post not found")
+	}
+	return userID, postID, nil
+}
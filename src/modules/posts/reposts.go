@@ -0,0 +1,154 @@
+package posts
+
+import (
+	"Backend/src/core/database"
+	"Backend/src/core/helpers"
+	"Backend/src/core/models"
+	"Backend/src/modules/feed"
+	"Backend/src/modules/linkpreview"
+	"Backend/src/modules/moderation"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repost publishes the post to the caller's followers. With content it becomes
+// a quote post; without, a plain repost. Reposting a plain repost points at its
+// original, and posts from non-public communities cannot be reposted.
+func Repost(c *fiber.Ctx) error {
+	db := database.DB
+
+	userId, ok := c.Locals("user_id").(string)
+	if !ok || userId == "" {
+		return helpers.HandleError(c, fiber.StatusUnauthorized, "Invalid or missing user_id", nil)
+	}
+	userID, err := uuid.Parse(userId)
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid user ID format", err)
+	}
+
+	postID, err := uuid.Parse(c.Params("post_id"))
+	if err != nil {
+		return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid post ID format", err)
+	}
+
+	var req struct {
+		Content    string `json:"content"`
+		Visibility string `json:"visibility"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return helpers.HandleError(c, fiber.StatusBadRequest, "Invalid request payload", err)
+		}
+	}
+	visibility, err := parseVisibility(req.Visibility, nil)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	content := strings.TrimSpace(req.Content)
+
+	original, err := loadVisiblePost(db, userID, postID)
+	if err != nil {
+		return helpers.HandleFiberError(c, err, "Failed to fetch post")
+	}
+	if original.PostType == models.PostTypeRepost && original.RepostOfID != nil {
+		if original, err = loadVisiblePost(db, userID, *original.RepostOfID); err != nil {
+			return helpers.HandleFiberError(c, err, "Failed to fetch post")
+		}
+	}
+	// Reposting would show the post to people its visibility excludes
+	if original.Visibility != models.PostVisibilityPublic {
+		return helpers.HandleError(c, fiber.StatusForbidden, "Only public posts can be reposted", nil)
+	}
+	if original.CommunityID != nil {
+		var public bool
+		if err := db.Raw("SELECT EXISTS (SELECT 1 FROM communities WHERE id = ? AND visibility = ?)", *original.CommunityID, models.CommunityVisibilityPublic).
+			Scan(&public).Error; err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to verify community", err)
+		}
+		if !public {
+			return helpers.HandleError(c, fiber.StatusForbidden, "Posts from private communities cannot be reposted", nil)
+		}
+	}
+
+	verdict := moderation.Verdict{Action: moderation.FilterAllow, Text: content}
+	if content != "" {
+		if verdict, err = screenContent(content); err != nil {
+			return helpers.HandleFiberError(c, err, "Failed to fetch post")
+		}
+		content = verdict.Text
+	}
+
+	postType := models.PostTypeQuote
+	if content == "" {
+		postType = models.PostTypeRepost
+
+		var alreadyReposted bool
+		if err := db.Raw("SELECT EXISTS (SELECT 1 FROM posts WHERE user_id = ? AND repost_of_id = ? AND post_type = ? AND deleted_at IS NULL)",
+			userID, original.ID, models.PostTypeRepost).Scan(&alreadyReposted).Error; err != nil {
+			return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to check existing reposts", err)
+		}
+		if alreadyReposted {
+			return helpers.HandleError(c, fiber.StatusConflict, "You have already reposted this post", nil)
+		}
+	}
+
+	repost := models.Post{
+		UserID:     userID,
+		Content:    content,
+		PostType:   postType,
+		Visibility: visibility,
+		RepostOfID: &original.ID,
+	}
+	if err := db.Table("posts").Create(&repost).Error; err != nil {
+		log.Printf("Error creating repost: %v\n", err)
+		return helpers.HandleError(c, fiber.StatusInternalServerError, "Failed to repost", err)
+	}
+
+	if postType == models.PostTypeQuote {
+		if verdict.Flagged() {
+			go moderation.FlagContent(db, models.ReportTargetPost, repost.ID.String(), userID, verdict.Labels)
+		}
+		if err := savePostTags(db, repost.ID, nil, ParseHashtags(content)); err != nil {
+			log.Printf("Error saving post tags: %v\n", err)
+		}
+		go tagPostAsync(db, repost.ID, content)
+		go linkpreview.Prefetch(db, content)
+		go notifyMentions(db, userID, ParseMentions(content), "post", repost.ID)
+	}
+
+	return helpers.HandleSuccess(c, fiber.StatusCreated, "Post reposted successfully", repost)
+}
+
+// postVisible reports whether the post is live and the user may see it.
+// Malformed IDs are reported as not visible.
+func postVisible(db *gorm.DB, userID, postID string) (bool, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return false, nil
+	}
+	if _, err := uuid.Parse(postID); err != nil {
+		return false, nil
+	}
+	var visible bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM posts WHERE posts.id = ? AND "+feed.VisiblePostsClause+")", postID, userID).
+		Scan(&visible).Error
+	return visible, err
+}
+
+// loadVisiblePost returns a live post the user may see. Errors are *fiber.Error
+// values carrying the response status, or database errors.
+func loadVisiblePost(db *gorm.DB, userID, postID uuid.UUID) (*models.Post, error) {
+	var post models.Post
+	err := db.Table("posts").Where("posts.id = ?", postID).Where(feed.VisiblePostsClause, userID).First(&post).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Post not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
@@ -0,0 +1,61 @@
+package posts
+
+import (
+	"Backend/src/core/models"
+	"Backend/src/modules/tagging"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const tagPredictionTimeout = 30 * time.Second
+
+var (
+	predictorOnce sync.Once
+	predictor     tagging.TagPredictor
+)
+
+func tagPredictor(db *gorm.DB) tagging.TagPredictor {
+	predictorOnce.Do(func() {
+		predictor = tagging.NewFromEnv(db)
+	})
+	return predictor
+}
+
+// tagPostAsync predicts tags for content and attaches them to the post. It runs
+// after the post is saved, and drops the result if the post was edited or
+// deleted in the meantime.
+func tagPostAsync(db *gorm.DB, postID uuid.UUID, content string) {
+	ctx, cancel := context.WithTimeout(context.Background(), tagPredictionTimeout)
+	defer cancel()
+
+	tags, err := tagPredictor(db).Predict(ctx, content)
+	if err != nil {
+		log.Printf("Error predicting tags for post %s: %v\n", postID, err)
+		return
+	}
+	log.Printf("Predicted tags for post %s: %v\n", postID, tags)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Lock the post so a concurrent edit cannot interleave with the tag refresh
+		var current []uuid.UUID
+		if err := tx.Raw("SELECT id FROM posts WHERE id = ? AND content = ? AND deleted_at IS NULL FOR UPDATE", postID, content).
+			Scan(&current).Error; err != nil {
+			return err
+		}
+		if len(current) == 0 {
+			return nil
+		}
+		if err := tx.Where("post_id = ? AND source = ?", postID, models.TagSourcePredicted).Delete(&models.PostTag{}).Error; err != nil {
+			return err
+		}
+		return savePostTags(tx, postID, tags, nil)
+	})
+	if err != nil {
+		log.Printf("Error saving predicted tags for post %s: %v\n", postID, err)
+	}
+}
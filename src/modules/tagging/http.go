@@ -0,0 +1,149 @@
+package tagging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned while the circuit breaker is rejecting calls.
+var ErrCircuitOpen = errors.New("tag prediction circuit breaker is open")
+
+// HTTPPredictor calls a remote model that accepts {"content": ...} and answers
+// {"tags": [...]}.
+type HTTPPredictor struct {
+	URL     string
+	Retries int
+	client  *http.Client
+	breaker *CircuitBreaker
+}
+
+// NewHTTPPredictor returns a predictor that gives each attempt timeout, retries
+// failed attempts up to retries times and stops calling the model while breaker is open.
+func NewHTTPPredictor(url string, timeout time.Duration, retries int, breaker *CircuitBreaker) *HTTPPredictor {
+	return &HTTPPredictor{
+		URL:     url,
+		Retries: retries,
+		client:  &http.Client{Timeout: timeout},
+		breaker: breaker,
+	}
+}
+
+func (p *HTTPPredictor) Predict(ctx context.Context, content string) ([]string, error) {
+	if content == "" {
+		return nil, fmt.Errorf("content cannot be empty for tag prediction")
+	}
+	if !p.breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	requestBody, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= p.Retries; attempt++ {
+		if attempt > 0 {
+			// Back off 200ms, 400ms, 800ms, ... between attempts
+			select {
+			case <-ctx.Done():
+				p.breaker.Failure()
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(100<<attempt) * time.Millisecond):
+			}
+		}
+
+		tags, err := p.call(ctx, requestBody)
+		if err == nil {
+			p.breaker.Success()
+			return tags, nil
+		}
+		lastErr = err
+	}
+
+	p.breaker.Failure()
+	return nil, fmt.Errorf("tag prediction failed after %d attempts: %w", p.Retries+1, lastErr)
+}
+
+func (p *HTTPPredictor) call(ctx context.Context, requestBody []byte) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tag prediction model responded with status: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return response.Tags, nil
+}
+
+// CircuitBreaker opens after threshold consecutive failures and lets a single
+// trial call through once cooldown has passed.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	trial     bool
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a call may be made now.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.trial || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// Success closes the breaker.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures, b.trial = 0, false
+}
+
+// Failure records a failed call, opening the breaker once the threshold is reached.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
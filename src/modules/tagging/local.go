@@ -0,0 +1,150 @@
+package tagging
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const (
+	localMaxTags        = 5
+	vocabularyRefreshAt = 10 * time.Minute
+)
+
+// LocalPredictor matches post content against the existing tags vocabulary and
+// ranks matches by TF-IDF, using how many posts already carry each tag as the
+// document frequency. It needs no network access and never blocks on a model.
+type LocalPredictor struct {
+	db *gorm.DB
+
+	mu         sync.RWMutex
+	vocabulary []vocabularyTag
+	totalPosts int
+	loadedAt   time.Time
+}
+
+type vocabularyTag struct {
+	tag    string
+	tokens []string
+	posts  int
+}
+
+func NewLocalPredictor(db *gorm.DB) *LocalPredictor {
+	return &LocalPredictor{db: db}
+}
+
+func (p *LocalPredictor) Predict(ctx context.Context, content string) ([]string, error) {
+	if err := p.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	tokens := tokenize(content)
+	if len(tokens) == 0 {
+		return []string{}, nil
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	type scoredTag struct {
+		tag   string
+		score float64
+	}
+	var scored []scoredTag
+	for _, candidate := range p.vocabulary {
+		matches := countMatches(tokens, candidate.tokens)
+		if matches == 0 {
+			continue
+		}
+		tf := float64(matches) / float64(len(tokens))
+		idf := math.Log(float64(p.totalPosts+1)/float64(candidate.posts+1)) + 1
+		scored = append(scored, scoredTag{tag: candidate.tag, score: tf * idf})
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].tag < scored[j].tag
+	})
+
+	tags := []string{}
+	for i := 0; i < len(scored) && i < localMaxTags; i++ {
+		tags = append(tags, scored[i].tag)
+	}
+	return tags, nil
+}
+
+// refresh reloads the vocabulary when it is older than vocabularyRefreshAt.
+func (p *LocalPredictor) refresh(ctx context.Context) error {
+	p.mu.RLock()
+	fresh := time.Since(p.loadedAt) < vocabularyRefreshAt
+	p.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	var rows []struct {
+		Tag   string
+		Posts int
+	}
+	if err := p.db.WithContext(ctx).Table("tags").
+		Select("tags.tag, COUNT(DISTINCT post_tags.post_id) AS posts").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Group("tags.tag").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	var totalPosts int64
+	if err := p.db.WithContext(ctx).Table("post_tags").Distinct("post_id").Count(&totalPosts).Error; err != nil {
+		return err
+	}
+
+	vocabulary := make([]vocabularyTag, 0, len(rows))
+	for _, row := range rows {
+		tokens := tokenize(row.Tag)
+		if len(tokens) == 0 {
+			continue
+		}
+		vocabulary = append(vocabulary, vocabularyTag{tag: row.Tag, tokens: tokens, posts: row.Posts})
+	}
+
+	p.mu.Lock()
+	p.vocabulary, p.totalPosts, p.loadedAt = vocabulary, int(totalPosts), time.Now()
+	p.mu.Unlock()
+	return nil
+}
+
+// tokenize lowercases text and splits it into letter/digit runs.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// countMatches counts the positions where phrase appears in tokens, accepting
+// a plural "s" on each token.
+func countMatches(tokens, phrase []string) int {
+	matches := 0
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		matched := true
+		for j, word := range phrase {
+			token := tokens[i+j]
+			if token != word && token != word+"s" {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches++
+		}
+	}
+	return matches
+}
@@ -0,0 +1,78 @@
+package tagging
+
+import (
+	"Backend/src/core/config"
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultModelURL = "https://ml-models-1rr1.onrender.com/predict"
+
+// TagPredictor suggests topic tags for a piece of post content.
+type TagPredictor interface {
+	Predict(ctx context.Context, content string) ([]string, error)
+}
+
+// FallbackPredictor asks Primary first and uses Fallback when it fails or
+// returns no tags.
+type FallbackPredictor struct {
+	Primary  TagPredictor
+	Fallback TagPredictor
+}
+
+func (p *FallbackPredictor) Predict(ctx context.Context, content string) ([]string, error) {
+	tags, err := p.Primary.Predict(ctx, content)
+	if err == nil && len(tags) > 0 {
+		return tags, nil
+	}
+	if err != nil {
+		log.Printf("Primary tag predictor failed, using fallback: %v\n", err)
+	}
+	return p.Fallback.Predict(ctx, content)
+}
+
+// NewFromEnv builds the predictor selected by TAG_PREDICTOR:
+//
+//	local - only the built-in keyword predictor
+//	http  - only the remote model at TAG_PREDICTOR_URL
+//	(default) the remote model, falling back to the keyword predictor
+//
+// TAG_PREDICTOR_TIMEOUT_MS, TAG_PREDICTOR_RETRIES, TAG_PREDICTOR_BREAKER_THRESHOLD
+// and TAG_PREDICTOR_BREAKER_COOLDOWN_SECONDS tune the HTTP predictor.
+func NewFromEnv(db *gorm.DB) TagPredictor {
+	local := NewLocalPredictor(db)
+
+	url := config.Config("TAG_PREDICTOR_URL")
+	if url == "" {
+		url = defaultModelURL
+	}
+	remote := NewHTTPPredictor(url,
+		time.Duration(envInt("TAG_PREDICTOR_TIMEOUT_MS", 3000))*time.Millisecond,
+		envInt("TAG_PREDICTOR_RETRIES", 2),
+		NewCircuitBreaker(
+			envInt("TAG_PREDICTOR_BREAKER_THRESHOLD", 5),
+			time.Duration(envInt("TAG_PREDICTOR_BREAKER_COOLDOWN_SECONDS", 60))*time.Second,
+		),
+	)
+
+	switch config.Config("TAG_PREDICTOR") {
+	case "local":
+		return local
+	case "http":
+		return remote
+	default:
+		return &FallbackPredictor{Primary: remote, Fallback: local}
+	}
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(config.Config(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
@@ -202,7 +202,7 @@ func UpdateProfile(c *fiber.Ctx) error {
 	type UpdateProfileRequest struct {
 		FirstName      string   `json:"first_name"`
 		LastName       string   `json:"last_name"`
-		Username       string   `json:username`
+		Username       string   `json:"username"`
 		Phone          string   `json:"phone"`
 		Gender         string   `json:"gender"`
 		Dob            time.Time   `json:"dob"`
@@ -24,6 +24,26 @@ CREATE TABLE IF NOT EXISTS badges (
     streak_required INT NOT NULL
 );
 
+CREATE TABLE IF NOT EXISTS bookmark_collections (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_type VARCHAR(20) NOT NULL CHECK (item_type IN ('post', 'event', 'workshop', 'project')),
+    item_id UUID NOT NULL,
+    collection_id INT REFERENCES bookmark_collections(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, item_type, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_created ON bookmarks(user_id, created_at DESC);
+
 CREATE TABLE IF NOT EXISTS colleges (
     id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
     college_name TEXT NOT NULL
@@ -33,27 +53,124 @@ CREATE TABLE IF NOT EXISTS comments (
     id UUID DEFAULT uuid_generate_v4() PRIMARY KEY,
     post_id UUID NOT NULL REFERENCES posts(id),
     user_id UUID NOT NULL REFERENCES users(id),
+    parent_id UUID REFERENCES comments(id) ON DELETE CASCADE,
     content TEXT NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    hidden_at TIMESTAMP
 );
 
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_comments_post_parent ON comments(post_id, parent_id, created_at);
+
 CREATE TABLE IF NOT EXISTS communities (
     id SERIAL PRIMARY KEY,                
     name VARCHAR(255) NOT NULL,           
     description TEXT,                      
-    created_at TIMESTAMP DEFAULT NOW()    
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public',
+    category VARCHAR(100),
+    icon_url TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (visibility IN ('public', 'request', 'invite'))
+);
+
+ALTER TABLE communities ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'request', 'invite'));
+ALTER TABLE communities ADD COLUMN IF NOT EXISTS category VARCHAR(100);
+ALTER TABLE communities ADD COLUMN IF NOT EXISTS icon_url TEXT;
+ALTER TABLE communities ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_communities_category ON communities(LOWER(category));
+
+CREATE TABLE IF NOT EXISTS community_bans (
+    id SERIAL PRIMARY KEY,
+    community_id INT NOT NULL,
+    user_id UUID NOT NULL,
+    banned_by UUID NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    FOREIGN KEY (community_id) REFERENCES communities(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (banned_by) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (community_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS community_invites (
+    id SERIAL PRIMARY KEY,
+    community_id INT NOT NULL,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    created_by UUID NOT NULL,
+    expires_at TIMESTAMP,
+    max_uses INT,
+    uses INT NOT NULL DEFAULT 0,
+    revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    FOREIGN KEY (community_id) REFERENCES communities(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS community_join_requests (
+    id SERIAL PRIMARY KEY,
+    community_id INT NOT NULL,
+    user_id UUID NOT NULL,
+    message TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    FOREIGN KEY (community_id) REFERENCES communities(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE (community_id, user_id),
+    CHECK (status IN ('pending', 'approved', 'denied'))
+);
+
+CREATE TABLE IF NOT EXISTS community_tags (
+    community_id INT NOT NULL REFERENCES communities(id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (community_id, tag_id)
 );
 
 CREATE TABLE IF NOT EXISTS community_members (
     id SERIAL PRIMARY KEY,                 
     user_id UUID NOT NULL,                 
     community_id INT NOT NULL,             
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    muted_until TIMESTAMP,
     joined_at TIMESTAMP DEFAULT NOW(),     
+    CHECK (role IN ('owner', 'admin', 'moderator', 'member')),
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
     FOREIGN KEY (community_id) REFERENCES communities(id) ON DELETE CASCADE,
     UNIQUE (user_id, community_id)        
 );
 
+ALTER TABLE community_members ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'moderator', 'member'));
+ALTER TABLE community_members ADD COLUMN IF NOT EXISTS muted_until TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_community_members_community_role ON community_members(community_id, role);
+
+-- Communities without an owner, such as those from before roles existed, are
+-- handed to their earliest member so someone can manage them
+UPDATE communities SET created_by = (
+    SELECT user_id FROM community_members
+    WHERE community_members.community_id = communities.id
+    ORDER BY joined_at, id
+    LIMIT 1
+)
+WHERE created_by IS NULL
+  AND NOT EXISTS (SELECT 1 FROM community_members WHERE community_id = communities.id AND role = 'owner');
+
+UPDATE community_members SET role = 'owner'
+FROM communities
+WHERE communities.id = community_members.community_id
+  AND communities.created_by = community_members.user_id
+  AND NOT EXISTS (SELECT 1 FROM community_members owners WHERE owners.community_id = communities.id AND owners.role = 'owner');
+
 CREATE TABLE IF NOT EXISTS connections (
     id SERIAL PRIMARY KEY,
     user_id UUID NOT NULL REFERENCES users(id),
@@ -61,6 +178,8 @@ CREATE TABLE IF NOT EXISTS connections (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX IF NOT EXISTS idx_connections_connection_created ON connections(connection_id, created_at);
+
 CREATE TABLE IF NOT EXISTS education_levels (
     id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
     level_name TEXT NOT NULL
@@ -83,9 +202,14 @@ CREATE TABLE IF NOT EXISTS events (
     tags VARCHAR(255),
     attendee_count INT,
     status event_status NOT NULL,
+    community_id INT REFERENCES communities(id) ON DELETE SET NULL,
     FOREIGN KEY (user_id) REFERENCES users(id)
 );
 
+ALTER TABLE events ADD COLUMN IF NOT EXISTS community_id INT REFERENCES communities(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_events_community_date ON events(community_id, date);
+
 CREATE TABLE IF NOT EXISTS fields_of_study (
     id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
     field_name TEXT NOT NULL
@@ -99,11 +223,31 @@ CREATE TABLE IF NOT EXISTS interests (
 CREATE TABLE IF NOT EXISTS likes (
     user_id UUID NOT NULL,
     post_id UUID NOT NULL,
+    reaction_type VARCHAR(20) NOT NULL DEFAULT 'like' CHECK (reaction_type IN ('like', 'celebrate', 'support', 'insightful', 'love', 'funny')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (user_id, post_id),
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
     FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
 );
 
+-- Existing likes become "like" reactions
+ALTER TABLE likes ADD COLUMN IF NOT EXISTS reaction_type VARCHAR(20) NOT NULL DEFAULT 'like';
+ALTER TABLE likes ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE likes DROP CONSTRAINT IF EXISTS likes_reaction_type_check;
+ALTER TABLE likes ADD CONSTRAINT likes_reaction_type_check CHECK (reaction_type IN ('like', 'celebrate', 'support', 'insightful', 'love', 'funny'));
+
+CREATE INDEX IF NOT EXISTS idx_likes_post_reaction ON likes(post_id, reaction_type);
+
+CREATE TABLE IF NOT EXISTS link_previews (
+    url TEXT PRIMARY KEY,
+    title TEXT,
+    description TEXT,
+    image_url TEXT,
+    site_name TEXT,
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE IF NOT EXISTS locations (
     id uuid PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
     name TEXT NOT NULL
@@ -115,10 +259,70 @@ CREATE TABLE IF NOT EXISTS messages (
     user_id UUID NOT NULL,                 
     message TEXT NOT NULL,                 
     created_at TIMESTAMP DEFAULT NOW(),    
+    hidden_at TIMESTAMP,
+    search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', message)) STORED,
     FOREIGN KEY (community_id) REFERENCES communities(id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', message)) STORED;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_messages_community_id ON messages (community_id, id);
+
+CREATE TABLE IF NOT EXISTS moderators (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS moderation_cases (
+    id SERIAL PRIMARY KEY,
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('post', 'comment', 'message', 'user')),
+    target_id TEXT NOT NULL,
+    target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'actioned', 'dismissed')),
+    report_count INT NOT NULL DEFAULT 0,
+    assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    auto_hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+-- One open case per target; resolved cases are kept and a new report opens a new case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_moderation_cases_open_target ON moderation_cases(target_type, target_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_moderation_cases_queue ON moderation_cases(status, report_count DESC, created_at);
+
+CREATE TABLE IF NOT EXISTS content_reports (
+    id SERIAL PRIMARY KEY,
+    case_id INT NOT NULL REFERENCES moderation_cases(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(30) NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate_speech', 'violence', 'nudity', 'misinformation', 'self_harm', 'other')),
+    details TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (case_id, reporter_id)
+);
+
+CREATE TABLE IF NOT EXISTS moderation_actions (
+    id SERIAL PRIMARY KEY,
+    case_id INT REFERENCES moderation_cases(id) ON DELETE SET NULL,
+    moderator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('hide', 'restore', 'delete', 'warn', 'suspend', 'dismiss', 'assign', 'flag')),
+    target_type VARCHAR(20) NOT NULL,
+    target_id TEXT NOT NULL,
+    target_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE moderation_actions DROP CONSTRAINT IF EXISTS moderation_actions_action_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_action_check CHECK (action IN ('hide', 'restore', 'delete', 'warn', 'suspend', 'dismiss', 'assign', 'flag'));
+
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_target ON moderation_actions(target_type, target_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_moderation_actions_moderator ON moderation_actions(moderator_id, created_at DESC);
+
 CREATE TABLE IF NOT EXISTS points_streak (
     user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
     total_points INT DEFAULT 0,
@@ -127,24 +331,146 @@ CREATE TABLE IF NOT EXISTS points_streak (
     last_attempted DATE 
 );
 
+CREATE TABLE IF NOT EXISTS polls (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+    closes_at TIMESTAMP,
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_polls_pending_close ON polls(closes_at) WHERE closed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id SERIAL PRIMARY KEY,
+    post_id UUID NOT NULL REFERENCES polls(post_id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    text VARCHAR(100) NOT NULL,
+    UNIQUE (post_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    post_id UUID NOT NULL REFERENCES polls(post_id) ON DELETE CASCADE,
+    option_id INT NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (option_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_post_user ON poll_votes(post_id, user_id);
+
 CREATE TABLE IF NOT EXISTS post_tags (
     id SERIAL PRIMARY KEY,
     post_id UUID REFERENCES posts(id),
     tag_id INT REFERENCES tags(id),
+    source VARCHAR(20) NOT NULL DEFAULT 'predicted' CHECK (source IN ('predicted', 'hashtag')),
     UNIQUE (post_id, tag_id)
 );
 
+ALTER TABLE post_tags ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'predicted' CHECK (source IN ('predicted', 'hashtag'));
+
+CREATE INDEX IF NOT EXISTS idx_post_tags_tag_source ON post_tags(tag_id, source);
+
 CREATE TABLE IF NOT EXISTS posts (
     id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     content TEXT NOT NULL,
     media_url TEXT,
+    community_id INT REFERENCES communities(id) ON DELETE CASCADE,
+    post_type VARCHAR(20) NOT NULL DEFAULT 'post' CHECK (post_type IN ('post', 'announcement', 'repost', 'quote', 'poll')),
+    repost_of_id UUID REFERENCES posts(id) ON DELETE CASCADE,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'followers', 'mutual', 'community', 'private')),
     likes_count INT DEFAULT 0,
     comments_count INT DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    hidden_at TIMESTAMP
+);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS community_id INT REFERENCES communities(id) ON DELETE CASCADE;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS post_type VARCHAR(20) NOT NULL DEFAULT 'post';
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_post_type_check;
+ALTER TABLE posts ADD CONSTRAINT posts_post_type_check CHECK (post_type IN ('post', 'announcement', 'repost', 'quote', 'poll'));
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS repost_of_id UUID REFERENCES posts(id) ON DELETE CASCADE;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'followers', 'mutual', 'community', 'private'));
+
+CREATE INDEX IF NOT EXISTS idx_posts_community_id ON posts(community_id);
+CREATE INDEX IF NOT EXISTS idx_posts_user_live ON posts(user_id, created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_repost_of_id ON posts(repost_of_id);
+
+CREATE TABLE IF NOT EXISTS post_attachments (
+    id SERIAL PRIMARY KEY,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    url TEXT NOT NULL,
+    media_type VARCHAR(10) NOT NULL CHECK (media_type IN ('image', 'video', 'audio')),
+    content_type VARCHAR(50) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INT,
+    height INT,
+    duration_seconds DOUBLE PRECISION,
+    thumbnail_url TEXT,
+    medium_url TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (post_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS post_drafts (
+    id UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL DEFAULT '',
+    post_type VARCHAR(20) NOT NULL DEFAULT 'post' CHECK (post_type IN ('post', 'announcement', 'poll')),
+    community_id INT REFERENCES communities(id) ON DELETE CASCADE,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'followers', 'mutual', 'community', 'private')),
+    attachments JSONB,
+    poll JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'scheduled', 'published', 'failed')),
+    scheduled_at TIMESTAMP,
+    post_id UUID REFERENCES posts(id) ON DELETE SET NULL,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+ALTER TABLE post_drafts ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'followers', 'mutual', 'community', 'private'));
+
+CREATE INDEX IF NOT EXISTS idx_post_drafts_user_status ON post_drafts(user_id, status, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_post_drafts_due ON post_drafts(scheduled_at) WHERE status = 'scheduled';
+
+CREATE TABLE IF NOT EXISTS post_edits (
+    id SERIAL PRIMARY KEY,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    edited_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_edits_post_id ON post_edits(post_id, edited_at DESC);
+
+CREATE TABLE IF NOT EXISTS post_recommendations (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    computed_at TIMESTAMP NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (user_id, post_id, computed_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_recommendations_user_run ON post_recommendations(user_id, computed_at, score DESC);
+CREATE INDEX IF NOT EXISTS idx_post_recommendations_computed_at ON post_recommendations(computed_at);
+
+CREATE TABLE IF NOT EXISTS post_views (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('impression', 'view')),
+    window_start TIMESTAMP NOT NULL,
+    PRIMARY KEY (post_id, user_id, kind, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_views_post_kind ON post_views(post_id, kind, window_start);
+
 CREATE TABLE IF NOT EXISTS projects (
     id UUID PRIMARY KEY,
     user_id UUID NOT NULL,
@@ -216,9 +542,12 @@ CREATE TABLE IF NOT EXISTS user_badges (
 CREATE TABLE IF NOT EXISTS user_interests (
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     interest_id UUID NOT NULL REFERENCES interests(interest_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
     PRIMARY KEY (user_id, interest_id)
 );
 
+ALTER TABLE user_interests ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT NOW();
+
 CREATE TABLE IF NOT EXISTS user_skills (
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     skill_id UUID NOT NULL REFERENCES skills(skill_id) ON DELETE CASCADE,
@@ -247,13 +576,16 @@ CREATE TABLE IF NOT EXISTS users (
     field_of_study_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000'::uuid,
     college_name_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000'::uuid,
     auth_id UUID REFERENCES auth(id),
-    for_first_time BOOLEAN DEFAULT TRUE
+    for_first_time BOOLEAN DEFAULT TRUE,
+    suspended_until TIMESTAMP WITH TIME ZONE,
     CONSTRAINT fk_location FOREIGN KEY (location_id) REFERENCES locations(id) ON DELETE SET NULL,
     CONSTRAINT fk_education_level FOREIGN KEY (education_level_id) REFERENCES education_levels(id) ON DELETE SET NULL,
     CONSTRAINT fk_field_of_study FOREIGN KEY (field_of_study_id) REFERENCES fields_of_study(id) ON DELETE SET NULL,
     CONSTRAINT fk_college_name FOREIGN KEY (college_name_id) REFERENCES colleges(id) ON DELETE SET NULL
 );
 
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMP WITH TIME ZONE;
+
 CREATE TABLE IF NOT EXISTS workshops (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     user_id UUID NOT NULL,
@@ -269,9 +601,14 @@ CREATE TABLE IF NOT EXISTS workshops (
     participant_limit INT NULL,   
     status workshop_status NOT NULL,
     registration_link TEXT NULL,
+    community_id INT REFERENCES communities(id) ON DELETE SET NULL,
     FOREIGN KEY (user_id) REFERENCES users(id)
 );
 
+ALTER TABLE workshops ADD COLUMN IF NOT EXISTS community_id INT REFERENCES communities(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_workshops_community_date ON workshops(community_id, date);
+
 CREATE OR REPLACE FUNCTION check_and_update_badges()
 RETURNS TRIGGER AS $$
 DECLARE